@@ -0,0 +1,159 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	z "./common"
+	zn "./networking"
+)
+
+func (g *Game) kick(broadcast bool, name string) {
+	defer g.recover()
+
+	if broadcast {
+		msg := g.Event("Kick")
+		msg.Params["Name"] = name
+		g.broadcast <- msg
+	}
+
+	g.remove(name)
+
+	g.announce(false, name+" was kicked", z.BoldColorRed)
+
+	if g.config.Server && g.phase == z.PHASE_LOBBY && g.lobby.AllReady() {
+		g.begin(true)
+	}
+}
+
+func (g *Game) disconnect(broadcast bool, name string) {
+	defer g.recover()
+
+	if !g.remove(name) {
+		return
+	}
+
+	if broadcast {
+		msg := g.Event("Disconnect")
+		msg.Params["Name"] = name
+		g.broadcast <- msg
+	}
+
+	g.announce(false, name+" left", z.BoldColorWhite)
+
+	if g.config.Server && g.phase == z.PHASE_LOBBY && g.lobby.AllReady() {
+		g.begin(true)
+	}
+}
+
+func (g *Game) remove(name string) bool {
+	removed := false
+
+	for _, p := range g.players.GetValues() {
+		if !p.Deleted() && strings.EqualFold(p.GetName(), name) {
+			g.lobby.Leave(p.GetID())
+
+			p.Stop(false)
+			p.Delete(false)
+
+			removed = true
+		}
+	}
+
+	g.chats.Leave(name)
+	g.sight.Unwatch(name)
+
+	g.referee.Lock()
+	g.referee.forget(name)
+	g.referee.Unlock()
+
+	return removed
+}
+
+func (g *Game) server() *zn.Server {
+	return zn.SharedServer(g.config.Bind, g.config.Port)
+}
+
+func (g *Game) admin(text string) bool {
+	fields := strings.Fields(text)
+
+	if len(fields) == 0 {
+		return false
+	}
+
+	switch fields[0] {
+	case "/kick", "/ban", "/unban", "/bans":
+
+	default:
+		return false
+	}
+
+	if !g.config.Multiplayer || !g.config.Server {
+		g.announce(false, "Only the host can do that", z.BoldColorRed)
+
+		return true
+	}
+
+	for _, s := range administer(g.server(), fields) {
+		g.announce(false, s, z.BoldColorWhite)
+	}
+
+	return true
+}
+
+func administer(server *zn.Server, fields []string) []string {
+	command := strings.TrimPrefix(fields[0], "/")
+
+	if command == "bans" {
+		bans := server.Bans()
+
+		if len(bans) == 0 {
+			return []string{"No bans"}
+		}
+
+		return []string{"Banned: " + strings.Join(bans, ", ")}
+	}
+
+	if len(fields) != 2 {
+		return []string{"Usage: " + command + " name|address"}
+	}
+
+	target := fields[1]
+
+	switch command {
+	case "kick":
+		n := server.Kick(target)
+
+		return []string{fmt.Sprintf("Kicked %d connection(s) of %s", n, target)}
+
+	case "ban":
+		server.Ban(target)
+		n := server.Kick(target)
+
+		return []string{fmt.Sprintf("Banned %s, kicked %d connection(s)", target, n)}
+
+	case "unban":
+		if !server.Unban(target) {
+			return []string{target + " is not banned"}
+		}
+
+		return []string{"Unbanned " + target}
+	}
+
+	return []string{"Unknown command " + command}
+}
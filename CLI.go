@@ -0,0 +1,524 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	tb "github.com/nsf/termbox-go"
+
+	z "./common"
+	zn "./networking"
+)
+
+type Command struct {
+	Name    string
+	Args    string
+	Summary string
+}
+
+var commands = []*Command{
+	{Name: "play", Args: "[flags]", Summary: "play a single player game, the default command"},
+	{Name: "host", Args: "[flags]", Summary: "host a multiplayer game and play in it"},
+	{Name: "join", Args: "[address[:port]] [flags]", Summary: "join a multiplayer game, or pick one on the LAN"},
+	{Name: "load", Args: "[slot] [flags]", Summary: "continue a saved single player game, or list the saves"},
+	{Name: "server", Args: "[flags]", Summary: "run a dedicated server without a display"},
+	{Name: "replay", Args: "file", Summary: "watch a recorded game"},
+	{Name: "editor", Args: "", Summary: "edit worlds, not available in this version"},
+	{Name: "help", Args: "[command]", Summary: "show the commands, or the flags of one command"},
+}
+
+var flagOutput io.Writer = os.Stderr
+
+var colors = map[string]tb.Attribute{
+	"blue":    z.BoldColorBlue,
+	"cyan":    z.BoldColorCyan,
+	"green":   z.BoldColorGreen,
+	"magenta": z.BoldColorMagenta,
+	"red":     z.BoldColorRed,
+	"white":   z.BoldColorWhite,
+	"yellow":  z.BoldColorYellow,
+}
+
+type colorFlag struct {
+	color *tb.Attribute
+}
+
+func (c *colorFlag) String() string {
+	if c.color == nil {
+		return ""
+	}
+
+	for name, color := range colors {
+		if color == *c.color {
+			return name
+		}
+	}
+
+	return ""
+}
+
+func (c *colorFlag) Set(s string) error {
+	color, ok := colors[strings.ToLower(s)]
+
+	if !ok {
+		return errors.New("colour must be one of " + strings.Join(colorNames(), ", "))
+	}
+
+	*c.color = color
+
+	return nil
+}
+
+func colorNames() []string {
+	names := []string{}
+
+	for name := range colors {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func command(name string) *Command {
+	for _, c := range commands {
+		if c.Name == name {
+			return c
+		}
+	}
+
+	return nil
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(flagOutput)
+	c := command(name)
+
+	fs.Usage = func() {
+		out := fs.Output()
+
+		fmt.Fprintf(out, "Usage: zahhak2 %s %s\n\n", c.Name, c.Args)
+		fmt.Fprintf(out, "%s%s.\n", strings.ToUpper(c.Summary[:1]), c.Summary[1:])
+
+		n := 0
+		fs.VisitAll(func(*flag.Flag) { n++ })
+
+		if n > 0 {
+			fmt.Fprintf(out, "\nFlags:\n")
+			fs.PrintDefaults()
+		}
+	}
+
+	return fs
+}
+
+func parseFlags(fs *flag.FlagSet, args []string) {
+	e := fs.Parse(args)
+
+	if e == flag.ErrHelp {
+		os.Exit(0)
+	}
+
+	if e != nil {
+		os.Exit(2)
+	}
+}
+
+func worldFlags(fs *flag.FlagSet, config *z.Config) {
+	fs.IntVar(&config.WorldWidth, "width", config.WorldWidth, "world width")
+	fs.IntVar(&config.WorldHeight, "height", config.WorldHeight, "world height")
+	fs.IntVar(&config.Capacity, "capacity", config.Capacity, "game objects per room")
+	fs.IntVar(&config.Difficulty, "difficulty", config.Difficulty, "chance in percent that monsters wander instead of hunting")
+	fs.BoolVar(&config.Fog, "fog", config.Fog, "players only see rooms within their vision")
+	fs.IntVar(&config.Vision, "vision", config.Vision, "how many rooms far players see with -fog")
+	fs.Int64Var(&config.Seed, "seed", config.Seed, "seed for the world and monsters, 0 for a random game")
+	fs.IntVar(&config.NumMonsters, "monsters", config.NumMonsters, "number of monsters")
+	fs.IntVar(&config.NumHealths, "healths", config.NumHealths, "number of healths")
+	fs.IntVar(&config.NumStrengths, "strengths", config.NumStrengths, "number of strengths")
+	fs.IntVar(&config.NumTreasures, "treasures", config.NumTreasures, "number of treasures")
+	fs.IntVar(&config.NumBombs, "bombs", config.NumBombs, "number of bombs")
+	fs.IntVar(&config.NumPortals, "portals", config.NumPortals, "number of portals")
+}
+
+func playerFlags(fs *flag.FlagSet, config *z.Config) {
+	fs.StringVar(&config.Name, "name", config.Name, "your name")
+	fs.Var(&colorFlag{&config.Color}, "color", "your colour: "+strings.Join(colorNames(), ", "))
+	fs.IntVar(&config.Volume, "volume", config.Volume, fmt.Sprintf("sound volume from 0 (off) to %d", z.VOLUME))
+	fs.StringVar(&config.Movement, "movement", config.Movement, "how keys move you: Walk keeps going, Step moves one room per press, Hold moves while the key is held")
+	fs.BoolVar(&config.Diagonal, "diagonal", config.Diagonal, "allow diagonal moves with the diagonal keys and the mouse")
+	keymapFlags(fs, config)
+}
+
+func keymapFlags(fs *flag.FlagSet, config *z.Config) {
+	fs.StringVar(&config.Keymap, "keymap", config.Keymap, "key layout ("+strings.Join(layoutNames(), ", ")+") or keymap file, default "+z.KEYMAP_FILE+" next to the config file")
+}
+
+func displayFlags(fs *flag.FlagSet, config *z.Config) {
+	fs.BoolVar(&config.Dynamic, "dynamic", config.Dynamic, "size the world and its contents to the terminal, turned off by -width and -height")
+}
+
+func screenFlags(fs *flag.FlagSet, config *z.Config) {
+	fs.StringVar(&config.Theme, "theme", config.Theme, "colour palette ("+strings.Join(paletteNames(), ", ")+") or theme file, default "+z.THEME_FILE+" next to the config file")
+	fs.StringVar(&config.Symbols, "symbols", config.Symbols, "symbols to draw with: "+z.SYMBOLS_UNICODE+" or "+z.SYMBOLS_ASCII+" for terminals without them, default from the theme")
+	fs.IntVar(&config.FPS, "fps", config.FPS, fmt.Sprintf("frames drawn per second, at most %d", z.FPS_MAX))
+}
+
+func mapFlags(fs *flag.FlagSet, config *z.Config) {
+	fs.BoolVar(&config.Minimap, "minimap", config.Minimap, "show a map of the whole world in the side panel")
+	fs.BoolVar(&config.Explored, "explored", config.Explored, "only show the parts of the minimap you have seen")
+}
+
+func matchFlags(fs *flag.FlagSet, config *z.Config) {
+	fs.StringVar(&config.Bind, "bind", config.Bind, "address or interface to listen on, empty for all")
+	fs.StringVar(&config.Port, "port", config.Port, "port to listen on")
+	fs.StringVar(&config.Match, "match", config.Match, "name of the match")
+	fs.StringVar(&config.Password, "password", config.Password, "password players need to join")
+	fs.IntVar(&config.Rounds, "rounds", config.Rounds, "rounds to play, 0 for no limit")
+	fs.BoolVar(&config.Lobby, "lobby", config.Lobby, "wait in a lobby until every player is ready")
+	fs.StringVar(&config.JoinMode, "join", config.JoinMode, "late joiners: Play, Spectator or Disabled")
+	fs.BoolVar(&config.Overview, "overview", config.Overview, "let spectators see the whole world through -fog")
+	fs.BoolVar(&config.Discovery, "discovery", config.Discovery, "announce the game on the local network")
+	fs.StringVar(&config.GameName, "title", config.GameName, "game name shown in LAN server browsers")
+	fs.BoolVar(&config.TLS, "tls", config.TLS, "serve secure websockets (wss)")
+	fs.StringVar(&config.CertFile, "cert", config.CertFile, "TLS certificate, a self-signed one is generated if missing")
+	fs.StringVar(&config.KeyFile, "key", config.KeyFile, "TLS private key")
+}
+
+func networkFlags(fs *flag.FlagSet, config *z.Config) {
+	fs.StringVar(&config.Impair, "impair", config.Impair, "simulate a bad network, e.g. latency=100ms,jitter=20ms,loss=0.02,reorder=0.01,bandwidth=16384,seed=1")
+}
+
+func gameFlags(name string, args []string) (*z.Config, error) {
+	config := z.NewConfig()
+
+	if name == "host" {
+		config.Name = "Host"
+	}
+
+	if e := loadProfile(config, args); e != nil {
+		return nil, e
+	}
+
+	fs := newFlagSet(name)
+	addressed := false
+	printed := configFlags(fs)
+
+	switch name {
+	case "play":
+		worldFlags(fs, config)
+		displayFlags(fs, config)
+		mapFlags(fs, config)
+		screenFlags(fs, config)
+		playerFlags(fs, config)
+		fs.StringVar(&config.Record, "record", config.Record, "record the game to a replay file")
+		fs.StringVar(&config.Load, "load", config.Load, "continue a saved game from a slot")
+
+	case "load":
+		mapFlags(fs, config)
+		screenFlags(fs, config)
+		playerFlags(fs, config)
+		fs.StringVar(&config.Record, "record", config.Record, "record the game to a replay file")
+
+		if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+			config.Load = args[0]
+			args = args[1:]
+		}
+
+	case "host":
+		worldFlags(fs, config)
+		displayFlags(fs, config)
+		mapFlags(fs, config)
+		screenFlags(fs, config)
+		playerFlags(fs, config)
+		matchFlags(fs, config)
+		networkFlags(fs, config)
+		fs.StringVar(&config.Record, "record", config.Record, "record the match to a replay file")
+
+	case "join":
+		mapFlags(fs, config)
+		screenFlags(fs, config)
+		playerFlags(fs, config)
+		networkFlags(fs, config)
+		fs.StringVar(&config.Team, "team", config.Team, "team to chat with")
+		fs.StringVar(&config.Match, "match", config.Match, "match to join, empty for the server's first match")
+		fs.StringVar(&config.Password, "password", config.Password, "password of the game")
+		fs.StringVar(&config.Token, "token", config.Token, "token that reserves your name on the server")
+		fs.BoolVar(&config.Spectator, "spectate", config.Spectator, "watch the game instead of playing")
+		fs.BoolVar(&config.TLS, "tls", config.TLS, "connect with secure websockets (wss)")
+		fs.StringVar(&config.Fingerprint, "fingerprint", config.Fingerprint, "pin the server's TLS certificate fingerprint")
+		fs.StringVar(&config.Record, "record", config.Record, "record the match as you see it to a replay file")
+
+		if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+			if e := address(config, args[0]); e != nil {
+				return nil, e
+			}
+
+			addressed = true
+			args = args[1:]
+		}
+	}
+
+	parseFlags(fs, args)
+
+	if name == "join" && !addressed && fs.NArg() > 0 {
+		if e := address(config, fs.Arg(0)); e != nil {
+			return nil, e
+		}
+
+		addressed = true
+	} else if name == "load" && config.Load == "" && fs.NArg() > 0 {
+		config.Load = fs.Arg(0)
+	} else if fs.NArg() > 0 {
+		return nil, errors.New("unexpected argument " + fs.Arg(0))
+	}
+
+	config.Browse = name == "join" && !addressed
+
+	if name == "load" && config.Load == "" {
+		return nil, errors.New("load needs a save slot, run 'zahhak2 load' to list them")
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "width" || f.Name == "height" {
+			config.Dynamic = false
+		}
+	})
+
+	config.Multiplayer = name == "host" || name == "join"
+	config.Server = name == "host"
+	config.Headless = false
+
+	if name == "host" && config.Match == "" {
+		config.Match = z.MATCH_NAME
+	}
+
+	if *printed {
+		fmt.Println(printConfig(config))
+
+		os.Exit(0)
+	}
+
+	return config, validateGame(name, config)
+}
+
+func address(config *z.Config, s string) error {
+	host, port, e := net.SplitHostPort(s)
+
+	if e != nil {
+		host, port = strings.Trim(s, "[]"), config.Port
+	}
+
+	if host == "" {
+		return errors.New("address " + s + " has no host")
+	}
+
+	if n, e := strconv.Atoi(port); e != nil || n < 1 || n > 65535 {
+		return errors.New("port " + port + " must be a number from 1 to 65535")
+	}
+
+	config.Host = host
+	config.Port = port
+
+	return nil
+}
+
+func validateGame(name string, config *z.Config) error {
+	if !z.ValidName(config.Name) {
+		return fmt.Errorf("name must be 1 to %d letters, digits, '-', '_' or '.'", z.NAME_LEN)
+	}
+
+	if config.Team != "" && !z.ValidName(config.Team) {
+		return fmt.Errorf("team must be 1 to %d letters, digits, '-', '_' or '.'", z.NAME_LEN)
+	}
+
+	if config.Volume < 0 || config.Volume > z.VOLUME {
+		return fmt.Errorf("volume must be from 0 to %d", z.VOLUME)
+	}
+
+	if e := validateFPS(config); e != nil {
+		return e
+	}
+
+	if !z.ValidMovement(config.Movement) {
+		return errors.New("movement must be Walk, Step or Hold")
+	}
+
+	if config.Impair != "" {
+		if _, e := zn.ParseImpairment(config.Impair); e != nil {
+			return e
+		}
+	}
+
+	switch name {
+	case "play", "load":
+		if config.Load != "" && !z.ValidName(config.Load) {
+			return errors.New("save slot " + config.Load + " is not a valid slot name")
+		}
+
+		return validateWorld(config)
+
+	case "host":
+		n, e := strconv.Atoi(config.Port)
+
+		if e != nil || n < 1 || n > 65535 {
+			return errors.New("port " + config.Port + " must be a number from 1 to 65535")
+		}
+
+		if !zn.IsTCPPortAvailable(config.Bind, n) {
+			return errors.New("port " + config.Port + " is already in use")
+		}
+
+		return validateServer(config)
+
+	case "join":
+		if config.Match != "" {
+			return validateMatch(config.Match)
+		}
+	}
+
+	return nil
+}
+
+func validateFPS(config *z.Config) error {
+	if config.FPS < 1 || config.FPS > z.FPS_MAX {
+		return fmt.Errorf("fps must be from 1 to %d", z.FPS_MAX)
+	}
+
+	return nil
+}
+
+func validateWorld(config *z.Config) error {
+	if config.WorldWidth < 2 || config.WorldHeight < 2 {
+		return errors.New("world must be at least 2x2")
+	}
+
+	if config.Capacity < 2 {
+		return errors.New("capacity must be at least 2")
+	}
+
+	if config.Difficulty < 0 || config.Difficulty > 100 {
+		return errors.New("difficulty must be a percentage from 0 to 100")
+	}
+
+	if config.Vision < 1 {
+		return errors.New("vision must be at least 1 room")
+	}
+
+	counts := []int{config.NumMonsters, config.NumHealths, config.NumStrengths, config.NumTreasures, config.NumBombs, config.NumPortals}
+	n := 0
+
+	for _, count := range counts {
+		if count < 0 {
+			return errors.New("numbers of game objects can not be negative")
+		}
+
+		n += count
+	}
+
+	if !config.Dynamic && n > config.WorldWidth*config.WorldHeight {
+		return fmt.Errorf("%d game objects do not fit in a %dx%d world", n, config.WorldWidth, config.WorldHeight)
+	}
+
+	return nil
+}
+
+func help(args []string) {
+	flagOutput = os.Stdout
+
+	if len(args) > 0 {
+		c := command(args[0])
+
+		if c == nil {
+			usageError("unknown command " + args[0])
+		}
+
+		switch c.Name {
+		case "play", "host", "join", "load":
+			gameFlags(c.Name, []string{"-h"})
+
+		case "server":
+			serverFlags(z.NewConfig(), []string{"-h"})
+
+		default:
+			newFlagSet(c.Name).Usage()
+		}
+
+		return
+	}
+
+	fmt.Println("Usage: zahhak2 [command] [flags]")
+	fmt.Println()
+	fmt.Println("Commands:")
+
+	for _, c := range commands {
+		fmt.Printf("  %-8s %s\n", c.Name, c.Summary)
+	}
+
+	fmt.Println()
+	fmt.Println("Run 'zahhak2 help command' for the flags of a command.")
+}
+
+func saves() {
+	slots := z.SaveSlots()
+
+	if len(slots) == 0 {
+		fmt.Println("No saved games in " + z.SAVE_DIR)
+
+		return
+	}
+
+	fmt.Println("Saved games:")
+
+	for _, slot := range slots {
+		s, e := z.ReadSave(slot)
+
+		if e != nil {
+			fmt.Printf("  %-16s %s\n", slot, e.Error())
+
+			continue
+		}
+
+		fmt.Printf("  %-16s round %d, saved %s\n", slot, s.Round, s.Saved.Format("2006-01-02 15:04"))
+	}
+
+	fmt.Println()
+	fmt.Println("Run 'zahhak2 load slot' to continue one.")
+}
+
+func editor(args []string) {
+	println("Error: the world editor is not available in this version of zahhak2")
+	println("Worlds are generated from the map flags, see 'zahhak2 help play'")
+
+	os.Exit(1)
+}
+
+func usageError(message string) {
+	println("Error: " + message)
+	println("Run 'zahhak2 help' for usage")
+
+	os.Exit(2)
+}
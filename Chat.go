@@ -0,0 +1,437 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	tb "github.com/nsf/termbox-go"
+
+	z "./common"
+)
+
+var profanity = []string{"arse", "ass", "bastard", "bitch", "crap", "damn", "dick", "fuck", "piss", "shit"}
+
+type Chat struct {
+	sync.RWMutex
+
+	open    bool
+	prompt  string
+	input   []rune
+	history z.IRing
+	sent    map[string][]time.Time
+	teams   map[string]string
+}
+
+func NewChat() *Chat {
+	history := z.NewRing()
+	history.SetCapacity(z.CHAT_HISTORY)
+
+	return &Chat{
+		history: history,
+		sent:    map[string][]time.Time{},
+		teams:   map[string]string{},
+	}
+}
+
+func (c *Chat) Open() {
+	c.Lock()
+	defer c.Unlock()
+
+	c.open = true
+	c.prompt = ""
+	c.input = []rune{}
+}
+
+func (c *Chat) Ask(prompt string) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.open = true
+	c.prompt = prompt
+	c.input = []rune{}
+}
+
+func (c *Chat) Close() string {
+	c.Lock()
+	defer c.Unlock()
+
+	text := string(c.input)
+
+	c.open = false
+	c.prompt = ""
+	c.input = []rune{}
+
+	return text
+}
+
+func (c *Chat) Opened() bool {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.open
+}
+
+func (c *Chat) Type(ch rune) {
+	c.Lock()
+	defer c.Unlock()
+
+	if len(c.input) < z.CHAT_LEN {
+		c.input = append(c.input, ch)
+	}
+}
+
+func (c *Chat) Backspace() {
+	c.Lock()
+	defer c.Unlock()
+
+	if len(c.input) > 0 {
+		c.input = c.input[:len(c.input)-1]
+	}
+}
+
+func (c *Chat) Input() string {
+	c.RLock()
+	defer c.RUnlock()
+
+	return string(c.input)
+}
+
+func (c *Chat) Prompt() string {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.prompt
+}
+
+func (c *Chat) History() z.IRing {
+	return c.history
+}
+
+func (c *Chat) Allow(id string) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	now := time.Now()
+	recent := []time.Time{}
+
+	for _, t := range c.sent[id] {
+		if now.Sub(t) < z.CHAT_PERIOD {
+			recent = append(recent, t)
+		}
+	}
+
+	if len(recent) >= z.CHAT_RATE {
+		c.sent[id] = recent
+
+		return false
+	}
+
+	c.sent[id] = append(recent, now)
+
+	return true
+}
+
+func (c *Chat) SetTeam(name, team string) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.teams[strings.ToLower(name)] = team
+}
+
+func (c *Chat) Leave(name string) {
+	c.Lock()
+	defer c.Unlock()
+
+	delete(c.teams, strings.ToLower(name))
+}
+
+func (c *Chat) Team(name string) string {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.teams[strings.ToLower(name)]
+}
+
+func (c *Chat) Outside(team string) []string {
+	c.RLock()
+	defer c.RUnlock()
+
+	names := []string{}
+
+	for name, t := range c.teams {
+		if t != team {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+func (g *Game) Chatting() bool {
+	return g.chats.Opened()
+}
+
+func (g *Game) OpenChat() {
+	g.chats.Open()
+}
+
+func (g *Game) ChatKey(key tb.Key, ch rune) {
+	defer g.recover()
+
+	switch key {
+	case tb.KeyEnter:
+		prompt := g.chats.Prompt()
+		text := g.chats.Close()
+
+		if prompt != "" {
+			g.SaveGame(strings.TrimSpace(text))
+		} else {
+			g.sendChat(text)
+		}
+
+	case tb.KeyEsc:
+		g.chats.Close()
+
+	case tb.KeyBackspace, tb.KeyBackspace2:
+		g.chats.Backspace()
+
+	case tb.KeySpace:
+		g.chats.Type(' ')
+
+	default:
+		if ch > 31 && ch < 127 {
+			g.chats.Type(ch)
+		}
+	}
+}
+
+func (g *Game) ScrollChat(lines int) {
+	g.canvas.Scroll(lines)
+}
+
+func (g *Game) sendChat(text string) {
+	text = strings.TrimSpace(text)
+
+	if text == "" {
+		return
+	}
+
+	if g.admin(text) {
+		return
+	}
+
+	scope, to := z.CHAT_ALL, ""
+
+	switch {
+	case strings.HasPrefix(text, "/team "):
+		team := strings.TrimSpace(text[6:])
+
+		if !z.ValidName(team) {
+			g.announce(false, "Invalid team name", z.BoldColorRed)
+
+			return
+		}
+
+		g.joinTeam(team)
+		g.announce(false, "Joined team "+team, z.BoldColorWhite)
+
+		return
+
+	case strings.HasPrefix(text, "/t "):
+		if g.config.Team == "" {
+			g.announce(false, "Not on a team, use /team name", z.BoldColorRed)
+
+			return
+		}
+
+		scope, text = z.CHAT_TEAM, text[3:]
+
+	case strings.HasPrefix(text, "/w "):
+		fields := strings.SplitN(text[3:], " ", 2)
+
+		if len(fields) < 2 {
+			g.announce(false, "Usage: /w name message", z.BoldColorRed)
+
+			return
+		}
+
+		scope, to, text = z.CHAT_WHISPER, fields[0], fields[1]
+	}
+
+	text, ok := g.filterChat(g.id, text)
+
+	if !ok {
+		g.announce(false, "Chat message dropped", z.BoldColorRed)
+
+		return
+	}
+
+	name, color := g.config.Name, g.config.Color
+
+	if g.config.Multiplayer {
+		msg := g.Event("Chat")
+		msg.Params["Name"] = name
+		msg.Params["Color"] = fmt.Sprintf("%d", color)
+		msg.Params["Scope"] = scope
+		msg.Params["To"] = to
+		msg.Params["Team"] = g.config.Team
+		msg.Params["Text"] = text
+		g.routeChat(msg)
+		g.broadcast <- msg
+	}
+
+	if !g.config.Multiplayer || g.config.Server || scope == z.CHAT_WHISPER {
+		g.chat(false, g.id, name, color, scope, to, g.config.Team, text)
+	}
+}
+
+func (g *Game) joinTeam(team string) {
+	g.config.Team = team
+
+	if !g.config.Multiplayer {
+		return
+	}
+
+	if g.config.Server {
+		g.chats.SetTeam(g.config.Name, team)
+
+		return
+	}
+
+	msg := g.Event("Team")
+	msg.Params["Name"] = g.config.Name
+	msg.Params["Team"] = team
+	g.broadcast <- msg
+}
+
+func (g *Game) team(broadcast bool, name, team string) {
+	defer g.recover()
+
+	g.chats.SetTeam(name, team)
+}
+
+func (g *Game) routeChat(m *z.Message) {
+	switch m.Params["Scope"] {
+	case z.CHAT_WHISPER:
+		m.To = m.Params["To"]
+
+	case z.CHAT_TEAM:
+		if !g.config.Server {
+			return
+		}
+
+		team := g.chats.Team(m.Params["Name"])
+		m.Params["Team"] = team
+		m.Hidden = g.chats.Outside(team)
+	}
+}
+
+func (g *Game) filterChat(id, text string) (string, bool) {
+	runes := []rune(strings.TrimSpace(text))
+
+	if len(runes) == 0 {
+		return "", false
+	}
+
+	if len(runes) > z.CHAT_LEN {
+		runes = runes[:z.CHAT_LEN]
+	}
+
+	for i, r := range runes {
+		if !unicode.IsPrint(r) {
+			runes[i] = ' '
+		}
+	}
+
+	text = censor(string(runes))
+
+	if g.config.Multiplayer && g.config.Server {
+		if !g.chats.Allow(id) {
+			return "", false
+		}
+	}
+
+	return text, true
+}
+
+func censor(text string) string {
+	words := strings.Fields(text)
+
+	for i, w := range words {
+		bare := strings.ToLower(strings.TrimFunc(w, func(r rune) bool { return !unicode.IsLetter(r) }))
+
+		for _, p := range profanity {
+			if bare == p {
+				words[i] = strings.Replace(strings.ToLower(w), p, strings.Repeat("*", len(p)), 1)
+
+				break
+			}
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+func (g *Game) chat(broadcast bool, gameID, name string, color tb.Attribute, scope, to, team, text string) {
+	defer g.recover()
+
+	line := ""
+
+	switch scope {
+	case z.CHAT_TEAM:
+		if team != g.config.Team {
+			return
+		}
+
+		line = "[" + team + "] " + name + ": " + text
+
+	case z.CHAT_WHISPER:
+		if !strings.EqualFold(to, g.config.Name) && gameID != g.id {
+			return
+		}
+
+		line = name + ">" + to + ": " + text
+
+	default:
+		line = name + ": " + text
+	}
+
+	lines := wrap(line, z.STATUS_LEN)
+
+	for i := len(lines) - 1; i >= 0; i-- {
+		status := &z.Status{Text: lines[i], Color: color}
+
+		g.chats.History().Enqueue(status)
+		g.statuses.Enqueue(status)
+	}
+}
+
+func wrap(text string, width int) []string {
+	lines := []string{}
+	runes := []rune(text)
+
+	for len(runes) > width {
+		lines = append(lines, string(runes[:width]))
+		runes = runes[width:]
+	}
+
+	return append(lines, string(runes))
+}
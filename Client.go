@@ -1,129 +1,231 @@
-/*
-Zahhak2, a Golang console game.
-Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
-This program is free software: you can redistribute it and/or modify
-it under the terms of the GNU General Public License as published by
-the Free Software Foundation, either version 3 of the License, or
-(at your option) any later version.
-This program is distributed in the hope that it will be useful,
-but WITHOUT ANY WARRANTY; without even the implied warranty of
-MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
-GNU General Public License for more details.
-You should have received a copy of the GNU General Public License
-along with this program.  If not, see <http://www.gnu.org/licenses/>.
-*/
-
-package main
-
-import (
-	"encoding/json"
-	"fmt"
-	"os"
-
-	tb "github.com/nsf/termbox-go"
-
-	z "./common"
-	zgo "./gameobjects"
-)
-
-func (g *Game) client() {
-	name := g.config.Name
-	m, ok := <-g.gameManager.networkManager.Messages
-
-	if !ok {
-		tb.Close()
-
-		println("Error: could not connect to server")
-
-		os.Exit(1)
-	}
-
-	p := m.Params["Config"]
-	config := &z.Config{}
-	bs := []byte(p[:])
-	e := json.Unmarshal(bs, config)
-
-	if e != nil {
-		tb.Close()
-
-		println("Error: could not get Config")
-
-		os.Exit(1)
-	}
-
-	g.config = config
-	g.config.Name = name
-	g.session = m.Params["Session"]
-	g.gameManager.SetSession(g.session)
-	g.display = true
-
-	g.createWorld()
-
-	mp := m.MultiParams["Strengths"]
-	g.jsonGOM(g.session, "Strengths", g.strengths, mp)
-	s := fmt.Sprintf("Initializing %d strengths", g.strengths.Len())
-	g.announce(false, s, z.BoldColorWhite)
-	mp = m.MultiParams["Treasures"]
-	g.jsonGOM(g.session, "Treasures", g.treasures, mp)
-	s = fmt.Sprintf("Initializing %d treasures", g.treasures.Len())
-	g.announce(false, s, z.BoldColorWhite)
-	mp = m.MultiParams["Healths"]
-	g.jsonGOM(g.session, "Healths", g.healths, mp)
-	s = fmt.Sprintf("Initializing %d healths", g.healths.Len())
-	g.announce(false, s, z.BoldColorWhite)
-
-	mp = m.MultiParams["Missles"]
-	g.jsonGOM(g.session, "Missles", g.missles, mp)
-	g.loadRooms(g.missles)
-	g.prepareMissles(g.missles)
-	s = fmt.Sprintf("Initializing %d missles", g.missles.Len())
-	g.announce(false, s, z.BoldColorWhite)
-	mp = m.MultiParams["Portals"]
-	g.jsonGOM(g.session, "Portals", g.portals, mp)
-	g.loadRooms(g.portals)
-	s = fmt.Sprintf("Initializing %d portals", g.portals.Len())
-	g.announce(false, s, z.BoldColorWhite)
-	mp = m.MultiParams["Bombs"]
-	g.jsonGOM(g.session, "Bombs", g.bombs, mp)
-	g.loadRooms(g.bombs)
-	s = fmt.Sprintf("Initializing %d bombs", g.bombs.Len())
-	g.announce(false, s, z.BoldColorWhite)
-
-	mp = m.MultiParams["Monsters"]
-	g.jsonGOM(g.session, "Monsters", g.monsters, mp)
-	g.loadRooms(g.monsters)
-	g.prepareMonsters(g.monsters)
-	s = fmt.Sprintf("Initializing %d monsters", g.monsters.Len())
-	g.announce(false, s, z.BoldColorWhite)
-	mp = m.MultiParams["Players"]
-	g.jsonGOM(g.session, "Players", g.players, mp)
-	g.loadRooms(g.players)
-	s = fmt.Sprintf("Initializing %d players", g.players.Len())
-	g.announce(false, s, z.BoldColorWhite)
-
-	g.announce(false, "Multiplayer mode", z.BoldColorWhite)
-	g.announce(false, "Connecting to game", z.BoldColorWhite)
-	g.announce(false, "Port: "+g.config.Port, z.BoldColorWhite)
-	g.announce(false, "IP: "+g.config.Host, z.BoldColorWhite)
-
-	id := z.UUID()
-	x, y := g.randomFreePlace()
-
-	g.newPlayer(true, g.config.Name, id, false)
-
-	igo, _ := g.players.Get(id)
-	g.player = igo.(*zgo.Player)
-
-	g.rooms.Enter(true, x, y, igo)
-
-	msg := g.Event("Run")
-	msg.Class = "Player"
-	msg.ID = id
-	g.broadcast <- msg
-
-	g.announce(false, "Initializing player", z.BoldColorWhite)
-
-	g.announce(false, "Finished initializing", z.BoldColorWhite)
-
-	g.pause(true, false)
-}
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	tb "github.com/nsf/termbox-go"
+
+	z "./common"
+)
+
+func (g *Game) client() {
+	name := g.config.Name
+	m, ok := <-g.gameManager.networkManager.Messages
+
+	if !ok {
+		tb.Close()
+
+		println("Error: could not connect to server")
+
+		os.Exit(1)
+	}
+
+	if m.Action == "Rejected" {
+		tb.Close()
+
+		println("Error: server rejected connection (" + m.Params["Code"] + "): " + m.Params["Reason"])
+
+		os.Exit(1)
+	}
+
+	p := m.Params["Config"]
+	config := &z.Config{}
+	bs := []byte(p[:])
+	e := json.Unmarshal(bs, config)
+
+	if e != nil {
+		tb.Close()
+
+		println("Error: could not get Config")
+
+		os.Exit(1)
+	}
+
+	config.Multiplayer = g.config.Multiplayer
+	config.Server = g.config.Server
+	config.Host = g.config.Host
+	config.Port = g.config.Port
+	config.Name = name
+	config.Color = g.config.Color
+	config.Movement = g.config.Movement
+	config.Diagonal = g.config.Diagonal
+	config.Minimap = g.config.Minimap
+	config.Explored = g.config.Explored
+	config.Spectator = g.config.Spectator
+	config.Headless = g.config.Headless
+	config.TLS = g.config.TLS
+	config.Fingerprint = g.config.Fingerprint
+	config.Impair = g.config.Impair
+	config.Team = g.config.Team
+	config.Match = g.config.Match
+	config.Password = g.config.Password
+	config.Token = g.config.Token
+	config.Volume = g.config.Volume
+	config.Record = g.config.Record
+	config.FPS = g.config.FPS
+	config.Keymap = g.config.Keymap
+	config.Theme = g.config.Theme
+	config.Symbols = g.config.Symbols
+
+	g.config = config
+	g.session = m.Params["Session"]
+	g.phase = m.Params["Phase"]
+	g.lobby.Load(m.MultiParams["Lobby"])
+	g.gameManager.SetSession(g.session)
+	g.display = true
+
+	g.createWorld()
+
+	g.loadState(m)
+
+	g.record()
+
+	g.announce(false, "Multiplayer mode", z.BoldColorWhite)
+	g.announce(false, "Connecting to game", z.BoldColorWhite)
+	g.announce(false, "Match: "+g.config.Match, z.BoldColorWhite)
+	g.announce(false, "Port: "+g.config.Port, z.BoldColorWhite)
+	g.announce(false, "IP: "+g.config.Host, z.BoldColorWhite)
+
+	if g.config.TLS {
+		g.announceFingerprint()
+	}
+
+	if g.config.Team != "" {
+		g.joinTeam(g.config.Team)
+	}
+
+	g.join()
+}
+
+func (g *Game) loadState(m *z.Message) {
+	mp := m.MultiParams["Strengths"]
+	g.jsonGOM(g.session, "Strengths", g.strengths, mp)
+	s := fmt.Sprintf("Initializing %d strengths", g.strengths.Len())
+	g.announce(false, s, z.BoldColorWhite)
+	mp = m.MultiParams["Treasures"]
+	g.jsonGOM(g.session, "Treasures", g.treasures, mp)
+	s = fmt.Sprintf("Initializing %d treasures", g.treasures.Len())
+	g.announce(false, s, z.BoldColorWhite)
+	mp = m.MultiParams["Healths"]
+	g.jsonGOM(g.session, "Healths", g.healths, mp)
+	s = fmt.Sprintf("Initializing %d healths", g.healths.Len())
+	g.announce(false, s, z.BoldColorWhite)
+
+	mp = m.MultiParams["Missles"]
+	g.jsonGOM(g.session, "Missles", g.missles, mp)
+	g.loadRooms(g.missles)
+	g.prepareMissles(g.missles)
+	s = fmt.Sprintf("Initializing %d missles", g.missles.Len())
+	g.announce(false, s, z.BoldColorWhite)
+	mp = m.MultiParams["Portals"]
+	g.jsonGOM(g.session, "Portals", g.portals, mp)
+	g.loadRooms(g.portals)
+	s = fmt.Sprintf("Initializing %d portals", g.portals.Len())
+	g.announce(false, s, z.BoldColorWhite)
+	mp = m.MultiParams["Bombs"]
+	g.jsonGOM(g.session, "Bombs", g.bombs, mp)
+	g.loadRooms(g.bombs)
+	s = fmt.Sprintf("Initializing %d bombs", g.bombs.Len())
+	g.announce(false, s, z.BoldColorWhite)
+
+	mp = m.MultiParams["Monsters"]
+	g.jsonGOM(g.session, "Monsters", g.monsters, mp)
+	g.loadRooms(g.monsters)
+	g.prepareMonsters(g.monsters)
+	s = fmt.Sprintf("Initializing %d monsters", g.monsters.Len())
+	g.announce(false, s, z.BoldColorWhite)
+	mp = m.MultiParams["Players"]
+	g.jsonGOM(g.session, "Players", g.players, mp)
+	g.loadRooms(g.players)
+	s = fmt.Sprintf("Initializing %d players", g.players.Len())
+	g.announce(false, s, z.BoldColorWhite)
+}
+
+func (g *Game) resync(broadcast bool, m *z.Message) {
+	defer g.recover()
+
+	player := g.player
+	goms := []*GameObjectMap{g.players, g.monsters, g.bombs, g.portals, g.missles, g.healths, g.strengths, g.treasures}
+
+	for _, gom := range goms {
+		for _, igo := range gom.GetValues() {
+			if player == nil || igo.GetID() != player.GetID() {
+				igo.Stop(false)
+				igo.Delete(false)
+			}
+		}
+	}
+
+	g.announce(false, "Resyncing with the server", z.BoldColorWhite)
+
+	g.resetWorld()
+	g.loadState(m)
+
+	if player != nil {
+		if igo, e := g.players.Get(player.GetID()); e == nil {
+			x, y := igo.GetPosition()
+			g.rooms.Leave(false, x, y, igo)
+		}
+
+		player.LoadRooms(g.rooms)
+		g.players.Set(player.GetID(), player)
+
+		x, y := player.GetPosition()
+		g.rooms.Enter(false, x, y, player)
+	}
+}
+
+func (g *Game) join() {
+	switch {
+	case g.config.Spectator:
+		g.phase = z.PHASE_SPECTATING
+		g.paused = false
+		g.scoreboard = true
+
+		g.announce(false, "Finished initializing", z.BoldColorWhite)
+		g.announce(false, "Spectating match", z.BoldColorWhite)
+		g.announce(false, "Press < > to follow players", z.BoldColorWhite)
+
+		g.CycleCamera(1)
+
+	case g.phase == z.PHASE_LOBBY:
+		g.spawnPlayer()
+
+		g.announce(false, "Finished initializing", z.BoldColorWhite)
+		g.openLobby()
+
+	case g.config.JoinMode == z.JOIN_SPECTATOR:
+		g.phase = z.PHASE_SPECTATING
+		g.paused = false
+
+		g.announce(false, "Finished initializing", z.BoldColorWhite)
+		g.announce(false, "Spectating match", z.BoldColorWhite)
+		g.announce(false, "Press enter to join", z.BoldColorWhite)
+
+	default:
+		g.spawnPlayer()
+
+		g.player.Start(true)
+
+		g.announce(false, "Finished initializing", z.BoldColorWhite)
+
+		g.pause(false, false)
+	}
+}
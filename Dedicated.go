@@ -0,0 +1,318 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode"
+
+	z "./common"
+	zn "./networking"
+)
+
+func serve(args []string) {
+	log.SetOutput(os.Stdout)
+
+	config := z.NewConfig()
+	config.Multiplayer = true
+	config.Server = true
+	config.Headless = true
+	config.Dynamic = false
+	config.Name = "Server"
+
+	count, e := serverFlags(config, args)
+
+	if e != nil {
+		usageError(e.Error())
+	}
+
+	ip, e := zn.LocalIP()
+
+	if e != nil {
+		log.Println("Server: could not find local address: " + e.Error())
+
+		ip = config.Host
+	}
+
+	config.Host = ip
+
+	log.Println("Server: Zahhak2 dedicated server")
+	log.Println("Server: settings " + printConfig(config))
+	log.Printf("Server: world %dx%d, %d monsters, %d treasures", config.WorldWidth, config.WorldHeight, config.NumMonsters, config.NumTreasures)
+	log.Println("Server: players connect with address " + ip + " " + config.Port)
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+
+	matches := NewMatches(config)
+
+	server := zn.SharedServer(config.Bind, config.Port)
+	server.OnCreate(matches.Open)
+
+	for i := 1; i <= *count; i++ {
+		name := config.Match
+
+		if *count > 1 {
+			name = fmt.Sprintf("%s %d", config.Match, i)
+		}
+
+		if e := matches.Open(name); e != nil {
+			fmt.Println("Error: " + e.Error())
+
+			os.Exit(1)
+		}
+	}
+
+	go console(server)
+
+	for !matches.Finished() {
+		select {
+		case <-interrupt:
+			log.Println("Server: interrupted, shutting down")
+
+			return
+
+		case <-time.After(1 * time.Second):
+		}
+	}
+
+	log.Println("Server: all rounds played, shutting down")
+}
+
+func serverFlags(config *z.Config, args []string) (*int, error) {
+	if e := loadProfile(config, args); e != nil {
+		return nil, e
+	}
+
+	fs := newFlagSet("server")
+	printed := configFlags(fs)
+
+	count := fs.Int("matches", 1, "matches to open on start")
+	tokens := fs.String("tokens", "", "JSON file mapping player names to tokens")
+	fs.StringVar(&config.Bind, "bind", config.Bind, "address or interface to listen on, empty for all")
+	fs.StringVar(&config.Port, "port", config.Port, "port to listen on")
+	worldFlags(fs, config)
+	fs.IntVar(&config.Rounds, "rounds", config.Rounds, "rounds to play before exiting, 0 for no limit")
+	fs.BoolVar(&config.Lobby, "lobby", config.Lobby, "wait in a lobby until every player is ready")
+	fs.StringVar(&config.JoinMode, "join", config.JoinMode, "late joiners: Play, Spectator or Disabled")
+	fs.BoolVar(&config.Overview, "overview", config.Overview, "let spectators see the whole world through -fog")
+	fs.BoolVar(&config.Discovery, "discovery", config.Discovery, "announce the game on the local network")
+	fs.StringVar(&config.GameName, "title", config.GameName, "game name shown in LAN server browsers")
+	fs.StringVar(&config.Match, "match", config.Match, "name of the first match")
+	fs.IntVar(&config.MaxMatches, "max-matches", config.MaxMatches, "matches that may run at once, including ones players create")
+	fs.StringVar(&config.Password, "password", config.Password, "password players need to join")
+	fs.BoolVar(&config.TLS, "tls", config.TLS, "serve secure websockets (wss)")
+	fs.StringVar(&config.CertFile, "cert", config.CertFile, "TLS certificate, a self-signed one is generated if missing")
+	fs.StringVar(&config.KeyFile, "key", config.KeyFile, "TLS private key")
+	fs.StringVar(&config.Record, "record", config.Record, "record each match to a replay file, the match name is added to the file name")
+	fs.StringVar(&config.Impair, "impair", config.Impair, "simulate a bad network, e.g. latency=100ms,jitter=20ms,loss=0.02,reorder=0.01,bandwidth=16384,seed=1")
+
+	parseFlags(fs, args)
+
+	if fs.NArg() > 0 {
+		return nil, errors.New("unexpected argument " + fs.Arg(0))
+	}
+
+	if *tokens != "" {
+		bs, e := ioutil.ReadFile(*tokens)
+
+		if e != nil {
+			return nil, e
+		}
+
+		config.Tokens = map[string]string{}
+
+		if e := json.Unmarshal(bs, &config.Tokens); e != nil {
+			return nil, errors.New("could not read " + *tokens + ": " + e.Error())
+		}
+	}
+
+	config.Multiplayer = true
+	config.Server = true
+	config.Headless = true
+	config.Dynamic = false
+
+	if config.Match == "" {
+		config.Match = z.MATCH_NAME
+	}
+
+	if *printed {
+		fmt.Println(printConfig(config))
+
+		os.Exit(0)
+	}
+
+	if *count < 1 || *count > config.MaxMatches {
+		return nil, errors.New("matches must be between 1 and max-matches")
+	}
+
+	return count, validateServer(config)
+}
+
+func validateServer(config *z.Config) error {
+	if e := validateWorld(config); e != nil {
+		return e
+	}
+
+	if e := validateMatch(config.Match); e != nil {
+		return e
+	}
+
+	if config.Impair != "" {
+		if _, e := zn.ParseImpairment(config.Impair); e != nil {
+			return e
+		}
+	}
+
+	for name, token := range config.Tokens {
+		if !z.ValidName(name) || token == "" {
+			return errors.New("tokens must map valid names to non-empty tokens")
+		}
+	}
+
+	if config.Rounds < 0 {
+		return errors.New("rounds can not be negative")
+	}
+
+	switch config.JoinMode {
+	case z.JOIN_PLAY, z.JOIN_SPECTATOR, z.JOIN_DISABLED:
+
+	default:
+		return errors.New("join must be Play, Spectator or Disabled")
+	}
+
+	return nil
+}
+
+func console(server *zn.Server) {
+	scanner := bufio.NewScanner(os.Stdin)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch strings.TrimPrefix(fields[0], "/") {
+		case "matches":
+			for _, m := range server.Matches() {
+				log.Printf("Server: %-16s %d connections %s", m.Name, m.Players, m.Session)
+			}
+
+		case "kick", "ban", "unban", "bans":
+			for _, s := range administer(server, fields) {
+				log.Println("Server: " + s)
+			}
+
+		default:
+			log.Println("Server: commands are matches, kick, ban, unban and bans")
+		}
+	}
+}
+
+func validateMatch(name string) error {
+	if len(name) > z.MATCH_NAME_LEN {
+		return fmt.Errorf("match name can be at most %d characters", z.MATCH_NAME_LEN)
+	}
+
+	if strings.TrimSpace(name) != name || name == "" {
+		return errors.New("match name can not be blank or padded with spaces")
+	}
+
+	for _, r := range name {
+		if !unicode.IsPrint(r) {
+			return errors.New("match name can only contain printable characters")
+		}
+	}
+
+	return nil
+}
+
+type Matches struct {
+	config *z.Config
+	games  []*Game
+
+	sync.Mutex
+}
+
+func NewMatches(config *z.Config) *Matches {
+	return &Matches{config: config}
+}
+
+func (ms *Matches) Open(name string) error {
+	ms.Lock()
+	defer ms.Unlock()
+
+	if e := validateMatch(name); e != nil {
+		return e
+	}
+
+	games := []*Game{}
+
+	for _, g := range ms.games {
+		if !g.Finished() {
+			games = append(games, g)
+		}
+	}
+
+	ms.games = games
+
+	if len(ms.games) >= ms.config.MaxMatches {
+		return errors.New("server is full, no more matches can be created")
+	}
+
+	config := *ms.config
+	config.Match = name
+
+	g := NewGame(&config)
+
+	if e := g.Start(); e != nil {
+		return e
+	}
+
+	g.Play()
+
+	ms.games = append(ms.games, g)
+
+	log.Printf("Server: %d of %d matches running", len(ms.games), ms.config.MaxMatches)
+
+	return nil
+}
+
+func (ms *Matches) Finished() bool {
+	ms.Lock()
+	defer ms.Unlock()
+
+	for _, g := range ms.games {
+		if !g.Finished() {
+			return false
+		}
+	}
+
+	return true
+}
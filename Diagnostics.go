@@ -0,0 +1,91 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tb "github.com/nsf/termbox-go"
+
+	z "./common"
+
+	zn "./networking"
+)
+
+func (g *Game) ToggleDiagnostics() {
+	defer g.recover()
+
+	g.diagnostics = !g.diagnostics
+}
+
+func (g *Game) renderDiagnostics() []*z.Status {
+	f := g.canvas.Frames()
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+
+	head := fmt.Sprintf("Render %dfps %.1fms max %.1fms", f.Rate, ms(f.Average), ms(f.Slowest))
+	cells := fmt.Sprintf(" cells %d idle %d sync %d", f.Cells, f.Skipped, f.Syncs)
+
+	return []*z.Status{{Text: head, Color: z.BoldColorCyan}, {Text: cells, Color: z.BoldColorCyan}}
+}
+
+func (g *Game) networkDiagnostics() []*z.Status {
+	lines := []*z.Status{{Text: "Network msg/s bytes/s", Color: z.BoldColorWhite}}
+
+	if g.gameManager == nil || g.gameManager.networkManager == nil {
+		return append(lines, &z.Status{Text: "Offline", Color: z.BoldColorYellow})
+	}
+
+	local, peers := g.gameManager.networkManager.Diagnostics()
+	local.Name = "Local"
+
+	lines = append(lines, diagnosticLines(local, z.BoldColorCyan)...)
+
+	for _, d := range peers {
+		lines = append(lines, diagnosticLines(d, z.BoldColorGreen)...)
+	}
+
+	return lines
+}
+
+func diagnosticLines(d *zn.Diagnostics, color tb.Attribute) []*z.Status {
+	rtt := "-"
+
+	if d.Latency > 0 {
+		rtt = fmt.Sprintf("%.1fms", float64(d.Latency)/float64(time.Millisecond))
+	}
+
+	if d.Total.Dropped > 0 {
+		color = z.BoldColorRed
+	}
+
+	head := fmt.Sprintf("%-10s %6s q%-4d d%d", d.Name, rtt, d.Queue, d.Total.Dropped)
+	rate := fmt.Sprintf(" in %d/%s out %d/%s", d.Rate.MessagesIn, byteCount(d.Rate.BytesIn), d.Rate.MessagesOut, byteCount(d.Rate.BytesOut))
+
+	return []*z.Status{{Text: head, Color: color}, {Text: rate, Color: color}}
+}
+
+func byteCount(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fM", float64(n)/(1<<20))
+
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fk", float64(n)/(1<<10))
+	}
+
+	return fmt.Sprintf("%d", n)
+}
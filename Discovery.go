@@ -0,0 +1,57 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	z "./common"
+	zn "./networking"
+)
+
+func (g *Game) announceLAN() {
+	g.announcer = zn.NewAnnouncer(zn.DISCOVERY_TARGETS, g.beacon)
+
+	if e := g.announcer.Run(); e != nil {
+		g.announce(false, e.Error(), z.BoldColorRed)
+
+		return
+	}
+
+	g.announce(false, "Announcing game on LAN", z.BoldColorWhite)
+}
+
+func (g *Game) beacon() *zn.Beacon {
+	players := 0
+
+	for _, p := range g.players.GetValues() {
+		if !p.Deleted() {
+			players++
+		}
+	}
+
+	return &zn.Beacon{
+		Session:     g.session,
+		Name:        g.config.GameName,
+		Match:       g.config.Match,
+		Host:        g.config.Host,
+		Port:        g.config.Port,
+		Players:     players,
+		WorldWidth:  g.config.WorldWidth,
+		WorldHeight: g.config.WorldHeight,
+		Phase:       g.phase,
+		TLS:         g.config.TLS,
+		Fingerprint: g.gameManager.networkManager.Fingerprint(),
+	}
+}
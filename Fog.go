@@ -0,0 +1,323 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	z "./common"
+)
+
+type Sight struct {
+	sync.Mutex
+
+	players map[string]bool
+	known   map[string]map[string][2]int
+}
+
+type Viewer struct {
+	X     int
+	Y     int
+	Alive bool
+}
+
+func NewSight() *Sight {
+	return &Sight{players: map[string]bool{}, known: map[string]map[string][2]int{}}
+}
+
+func (s *Sight) Watch(name string) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.players[strings.ToLower(name)] = true
+	delete(s.known, strings.ToLower(name))
+}
+
+func (s *Sight) Unwatch(name string) {
+	s.Lock()
+	defer s.Unlock()
+
+	delete(s.players, strings.ToLower(name))
+	delete(s.known, strings.ToLower(name))
+}
+
+func (s *Sight) Watched(name string) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.players[strings.ToLower(name)]
+}
+
+func (s *Sight) watched() []string {
+	names := []string{}
+
+	for name := range s.players {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func (s *Sight) Reset() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.known = map[string]map[string][2]int{}
+}
+
+func (s *Sight) get(name, id string) ([2]int, bool) {
+	p, ok := s.known[name][id]
+
+	return p, ok
+}
+
+func (s *Sight) set(name, id string, x, y int) {
+	if s.known[name] == nil {
+		s.known[name] = map[string][2]int{}
+	}
+
+	s.known[name][id] = [2]int{x, y}
+}
+
+func (s *Sight) unset(name, id string) {
+	delete(s.known[name], id)
+}
+
+func (g *Game) fogged(class string) bool {
+	return class == "Player" || class == "Monster" || class == "Missle"
+}
+
+func (g *Game) public(class, action string) bool {
+	switch action {
+	case "NewPlayer", "Announce", "Run", "Start", "Stop", "SetMovement", "SetName", "SetID", "ChangeTreasure":
+		return true
+
+	case "Delete":
+		return class == "Player"
+	}
+
+	return false
+}
+
+func (g *Game) owner(class, id string) string {
+	if class != "Player" {
+		return ""
+	}
+
+	igo, e := g.players.Get(id)
+
+	if e != nil {
+		return ""
+	}
+
+	return strings.ToLower(igo.GetName())
+}
+
+func (g *Game) viewers(names []string) map[string]*Viewer {
+	viewers := map[string]*Viewer{}
+
+	for _, name := range names {
+		viewers[name] = &Viewer{}
+	}
+
+	for _, igo := range g.players.GetValues() {
+		if igo.Deleted() {
+			continue
+		}
+
+		x, y := igo.GetPosition()
+		viewers[strings.ToLower(igo.GetName())] = &Viewer{X: x, Y: y, Alive: true}
+	}
+
+	return viewers
+}
+
+func (g *Game) sees(viewer *Viewer, x, y int) bool {
+	if !viewer.Alive {
+		return false
+	}
+
+	return z.Visible(viewer.X, viewer.Y, x, y, g.config.Vision, g.config.WorldWidth, g.config.WorldHeight, nil)
+}
+
+func (g *Game) filterSight(m *z.Message) {
+	if !g.config.Fog {
+		return
+	}
+
+	class, id := m.Class, m.ID
+
+	if m.Action == "Enter" || m.Action == "Leave" {
+		class, id = m.Params["Class"], m.Params["ID"]
+	}
+
+	if m.Action == "NewMissle" {
+		class, id = "Missle", m.Params["ID"]
+	}
+
+	if !g.fogged(class) {
+		return
+	}
+
+	x, ex := strconv.Atoi(m.Params["X"])
+	y, ey := strconv.Atoi(m.Params["Y"])
+	owner := g.owner(class, id)
+
+	g.sight.Lock()
+	defer g.sight.Unlock()
+
+	if m.To != "" {
+		switch m.Action {
+		case "Enter":
+			g.sight.set(strings.ToLower(m.To), id, x, y)
+
+		case "Leave":
+			g.sight.unset(strings.ToLower(m.To), id)
+		}
+
+		return
+	}
+
+	for name, viewer := range g.viewers(g.sight.watched()) {
+		_, known := g.sight.get(name, id)
+
+		if name == owner {
+			continue
+		}
+
+		switch m.Action {
+		case "Enter", "NewMissle":
+			if ex == nil && ey == nil && g.sees(viewer, x, y) {
+				g.sight.set(name, id, x, y)
+
+				continue
+			}
+
+		case "Leave":
+			if known {
+				g.sight.unset(name, id)
+
+				continue
+			}
+
+		case "Delete":
+			if known {
+				g.sight.unset(name, id)
+
+				continue
+			}
+
+			if g.public(class, m.Action) {
+				continue
+			}
+
+		default:
+			if known || g.public(class, m.Action) {
+				continue
+			}
+		}
+
+		m.Hidden = append(m.Hidden, name)
+	}
+}
+
+func (g *Game) watchSight() {
+	go func() {
+		defer g.recover()
+
+		for !g.Finished() {
+			time.Sleep(z.FOG_SWEEP)
+
+			g.sweepSight()
+		}
+	}()
+}
+
+func (g *Game) sweepSight() {
+	creatures := append(g.players.GetValues(), g.monsters.GetValues()...)
+
+	g.sight.Lock()
+	names := g.sight.watched()
+	g.sight.Unlock()
+
+	for name, viewer := range g.viewers(names) {
+		for _, igo := range creatures {
+			if igo.Deleted() || (igo.GetClass() == "Player" && strings.EqualFold(igo.GetName(), name)) {
+				continue
+			}
+
+			id := igo.GetID()
+			x, y := igo.GetPosition()
+			visible := g.sees(viewer, x, y)
+
+			g.sight.Lock()
+			p, known := g.sight.get(name, id)
+			g.sight.Unlock()
+
+			if known && (!visible || p != [2]int{x, y}) {
+				g.reveal(name, "Leave", igo.GetClass(), id, p[0], p[1])
+			}
+
+			if visible && (!known || p != [2]int{x, y}) {
+				g.reveal(name, "Enter", igo.GetClass(), id, x, y)
+			}
+		}
+	}
+}
+
+func (g *Game) reveal(name, action, class, id string, x, y int) {
+	msg := g.Event(action)
+	msg.Params["Class"] = class
+	msg.Params["ID"] = id
+	msg.Params["X"] = strconv.Itoa(x)
+	msg.Params["Y"] = strconv.Itoa(y)
+	msg.To = name
+
+	g.broadcast <- msg
+}
+
+func (g *Game) hideState(m *z.Message) {
+	if !g.config.Fog {
+		return
+	}
+
+	for _, key := range []string{"Players", "Monsters", "Missles"} {
+		m.MultiParams[key] = g.hideJSON(m.MultiParams[key])
+	}
+}
+
+func (g *Game) hideJSON(objects []string) []string {
+	hidden := []string{}
+
+	for _, o := range objects {
+		fields := map[string]interface{}{}
+
+		if e := json.Unmarshal([]byte(o), &fields); e != nil {
+			continue
+		}
+
+		fields["X"], fields["Y"] = -1, -1
+
+		bs, _ := json.Marshal(fields)
+		hidden = append(hidden, string(bs))
+	}
+
+	return hidden
+}
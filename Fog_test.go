@@ -0,0 +1,129 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"testing"
+
+	z "./common"
+	zgo "./gameobjects"
+)
+
+func newFogGame() *Game {
+	g := NewGame(z.NewConfig())
+	g.config.Fog, g.config.Vision = true, 2
+	g.config.WorldWidth, g.config.WorldHeight = 20, 20
+	g.broadcast = make(chan *z.Message, 100)
+	g.statuses = z.NewRing()
+	g.resetWorld()
+
+	for _, p := range []struct {
+		name, id string
+		x, y     int
+	}{{"a", "pa", 0, 0}, {"b", "pb", 10, 10}} {
+		o := zgo.NewPlayer(false, g.broadcast, g.id, 20, 20, g.rooms, p.name, p.id, '@', z.PLAYER_COLOR)
+		o.SetPosition(false, p.x, p.y)
+		g.players.Set(p.id, o)
+		g.sight.Watch(p.name)
+	}
+
+	g.sight.set("b", "m1", 10, 11)
+
+	return g
+}
+
+func hidden(m *z.Message, name string) bool {
+	for _, n := range m.Hidden {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestFilterSightHidesUnseenObjects(t *testing.T) {
+	g := newFogGame()
+
+	for _, action := range []string{"ChangeHealth", "ChangeStrength", "Stay", "Sfx"} {
+		m := z.NewMessage("Monster", "m1", action)
+		g.filterSight(m)
+
+		if !hidden(m, "a") || hidden(m, "b") {
+			t.Errorf("%s hidden from %v, want only a", action, m.Hidden)
+		}
+	}
+
+	m := z.NewMessage("Missle", "x1", "Next")
+	g.filterSight(m)
+
+	if !hidden(m, "a") || !hidden(m, "b") {
+		t.Errorf("unseen missle hidden from %v, want a and b", m.Hidden)
+	}
+
+	m = z.NewMessage("Monster", "m1", "Delete")
+	g.filterSight(m)
+
+	if !hidden(m, "a") || hidden(m, "b") {
+		t.Errorf("delete hidden from %v, want only a", m.Hidden)
+	}
+
+	if _, known := g.sight.get("b", "m1"); known {
+		t.Error("deleted monster still known")
+	}
+}
+
+func TestFilterSightOwnAndPublicEvents(t *testing.T) {
+	g := newFogGame()
+
+	m := z.NewMessage("Player", "pb", "ChangeHealth")
+	g.filterSight(m)
+
+	if hidden(m, "b") || !hidden(m, "a") {
+		t.Errorf("health of b hidden from %v, want only a", m.Hidden)
+	}
+
+	for _, action := range []string{"Announce", "NewPlayer", "Delete", "ChangeTreasure"} {
+		m := z.NewMessage("Player", "pb", action)
+		g.filterSight(m)
+
+		if len(m.Hidden) > 0 {
+			t.Errorf("%s hidden from %v", action, m.Hidden)
+		}
+	}
+}
+
+func TestSpectatorsAreFogged(t *testing.T) {
+	g := newFogGame()
+
+	if _, ok := g.newClient(true, "watcher", "", ""); !ok {
+		t.Fatal("spectator rejected")
+	}
+
+	if !g.sight.Watched("watcher") {
+		t.Error("spectator sees through the fog")
+	}
+
+	g.config.Overview = true
+
+	if _, ok := g.newClient(true, "judge", "", ""); !ok {
+		t.Fatal("spectator rejected")
+	}
+
+	if g.sight.Watched("judge") {
+		t.Error("spectator fogged with -overview")
+	}
+}
@@ -1,445 +1,683 @@
-/*
-Zahhak2, a Golang console game.
-Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
-This program is free software: you can redistribute it and/or modify
-it under the terms of the GNU General Public License as published by
-the Free Software Foundation, either version 3 of the License, or
-(at your option) any later version.
-This program is distributed in the hope that it will be useful,
-but WITHOUT ANY WARRANTY; without even the implied warranty of
-MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
-GNU General Public License for more details.
-You should have received a copy of the GNU General Public License
-along with this program.  If not, see <http://www.gnu.org/licenses/>.
-*/
-
-package main
-
-import (
-	"errors"
-	"fmt"
-	"sync"
-	"time"
-
-	tb "github.com/nsf/termbox-go"
-
-	zc "./canvas"
-	z "./common"
-	zgo "./gameobjects"
-	zm "./music"
-)
-
-type Game struct {
-	sync.RWMutex
-
-	config      *z.Config
-	id          string
-	session     string
-	broadcast   chan *z.Message
-	gameManager *GameManager
-
-	rooms  *Rooms
-	player z.IPlayer
-
-	players   *GameObjectMap
-	monsters  *GameObjectMap
-	bombs     *GameObjectMap
-	portals   *GameObjectMap
-	missles   *GameObjectMap
-	healths   *GameObjectMap
-	strengths *GameObjectMap
-	treasures *GameObjectMap
-
-	statuses z.IRing
-	canvas   *zc.Canvas
-	music    *zm.Music
-
-	display bool
-	paused  bool
-}
-
-func NewGame(config *z.Config) *Game {
-	return &Game{
-		paused:  true,
-		config:  config,
-		id:      z.UUID(),
-		session: z.UUID(),
-	}
-}
-
-func (g *Game) Event(action string) *z.Message {
-	m := z.NewMessage("Game", g.id, action)
-
-	return m
-}
-
-func (g *Game) Start() {
-	defer g.recover()
-
-	g.config.Init()
-
-	g.init()
-
-	g.initMultiplayer()
-}
-
-func (g *Game) init() {
-	g.broadcast = make(chan *z.Message, 1024)
-
-	g.gameManager = NewGameManager(g)
-	g.gameManager.Run()
-}
-
-func (g *Game) initMultiplayer() {
-	if g.config.Multiplayer {
-		if g.config.Server {
-			g.display = true
-
-			g.initGame()
-
-			g.runCreatures(false, nil)
-
-			g.announce(false, "Multiplayer mode", z.BoldColorWhite)
-			g.announce(false, "Hosting game", z.BoldColorWhite)
-			g.announce(false, "Port: "+g.config.Port, z.BoldColorWhite)
-			g.announce(false, "IP: "+g.config.Host, z.BoldColorWhite)
-			g.announce(false, "Waiting for others", z.BoldColorWhite)
-			g.announce(false, "Press enter to begin", z.BoldColorWhite)
-		} else {
-			g.client()
-		}
-	} else {
-		g.display = true
-
-		g.initGame()
-
-		g.announce(false, "Single player mode", z.BoldColorWhite)
-
-		g.runCreatures(false, nil)
-
-		g.pause(false, false)
-	}
-}
-
-func (g *Game) announce(broadcast bool, text string, color tb.Attribute) {
-	if broadcast {
-		msg := g.Event("Announce")
-		msg.Params["Status"] = text
-		msg.Params["Color"] = fmt.Sprintf("%d", color)
-		g.broadcast <- msg
-	}
-
-	status := &z.Status{Text: text, Color: color}
-
-	go g.statuses.Enqueue(status)
-}
-
-func (g *Game) initGame() {
-	g.createWorld()
-
-	g.initPlayer()
-
-	g.initHealths()
-
-	g.initStrengths()
-
-	g.initTreasures()
-
-	g.initBombs()
-
-	g.initPortals()
-
-	g.initMonsters()
-}
-
-func (g *Game) createWorld() {
-	g.statuses = z.NewRing()
-	g.statuses.SetCapacity(g.config.NumMsgsDisplay)
-
-	for i := 0; i < g.config.NumMsgsDisplay; i++ {
-		g.announce(false, "", z.BoldColorWhite)
-	}
-
-	g.music = zm.NewMusic()
-	g.music.Run()
-	g.music.Background()
-
-	g.announce(false, "Creating world", z.BoldColorWhite)
-
-	g.rooms = NewRooms(g.session, g.broadcast, g.config.Capacity, g.config.WorldWidth, g.config.WorldHeight)
-
-	g.canvas = zc.NewCanvas(g.config, g.rooms, g.statuses)
-	g.players = NewGameObjectMap()
-	g.healths = NewGameObjectMap()
-	g.strengths = NewGameObjectMap()
-	g.treasures = NewGameObjectMap()
-	g.bombs = NewGameObjectMap()
-	g.portals = NewGameObjectMap()
-	g.monsters = NewGameObjectMap()
-	g.missles = NewGameObjectMap()
-}
-
-func (g *Game) initPlayer() {
-	g.announce(false, "Initializing player", z.BoldColorWhite)
-
-	id := z.UUID()
-	g.newPlayer(false, g.config.Name, id, false)
-	igo, _ := g.players.Get(id)
-	g.player = igo.(*zgo.Player)
-
-	x, y := g.config.WorldWidth/2, g.config.WorldHeight/2
-	g.player.Move(false, x, y)
-	g.player.Next(false, 0, 0)
-}
-
-func (g *Game) initHealths() {
-	s := fmt.Sprintf("Initializing %d healths", g.config.NumHealths)
-	g.announce(false, s, z.BoldColorWhite)
-
-	for i := 0; i < g.config.NumHealths; i++ {
-		health := zgo.NewHealth(g.broadcast)
-		g.healths.Set(health.GetID(), health)
-		g.placeRandomly(false, health)
-	}
-}
-
-func (g *Game) initStrengths() {
-	s := fmt.Sprintf("Initializing %d strengths", g.config.NumStrengths)
-	g.announce(false, s, z.BoldColorWhite)
-
-	for i := 0; i < g.config.NumStrengths; i++ {
-		strength := zgo.NewStrength(g.broadcast)
-		g.strengths.Set(strength.GetID(), strength)
-		g.placeRandomly(false, strength)
-	}
-}
-
-func (g *Game) initTreasures() {
-	s := fmt.Sprintf("Initializing %d treasures", g.config.NumTreasures)
-	g.announce(false, s, z.BoldColorWhite)
-
-	for i := 0; i < g.config.NumTreasures; i++ {
-		treasure := zgo.NewTreasure(g.broadcast)
-		g.treasures.Set(treasure.GetID(), treasure)
-		g.placeRandomly(false, treasure)
-	}
-}
-
-func (g *Game) initBombs() {
-	s := fmt.Sprintf("Initializing %d bombs", g.config.NumBombs)
-	g.announce(false, s, z.BoldColorWhite)
-
-	for i := 0; i < g.config.NumBombs; i++ {
-		bomb := zgo.NewBomb(g.broadcast, g.rooms)
-		g.bombs.Set(bomb.GetID(), bomb)
-		g.placeRandomly(false, bomb)
-	}
-}
-
-func (g *Game) initPortals() {
-	s := fmt.Sprintf("Initializing %d portals", g.config.NumPortals)
-	g.announce(false, s, z.BoldColorWhite)
-
-	for i := 0; i < g.config.NumPortals; i++ {
-		portal := zgo.NewPortal(g.broadcast, g.config.WorldWidth, g.config.WorldHeight, g.rooms)
-		g.portals.Set(portal.GetID(), portal)
-		g.placeRandomly(false, portal)
-	}
-}
-
-func (g *Game) initMonsters() {
-	s := fmt.Sprintf("Initializing %d monsters", g.config.NumMonsters)
-	g.announce(false, s, z.BoldColorWhite)
-
-	for i := 0; i < g.config.NumMonsters; i++ {
-		monster := zgo.NewMonster(g.broadcast, g.config.WorldWidth, g.config.WorldHeight, g.rooms, g.players, g.config.Difficulty)
-		g.monsters.Set(monster.GetID(), monster)
-		g.placeRandomly(false, monster)
-	}
-}
-
-func (g *Game) placeRandomly(broadcast bool, igo z.IGameObject) {
-	class := igo.GetClass()
-	x, y := g.randomFreePlace()
-
-	if class == "Player" || class == "Monster" {
-		c, _ := igo.(z.ICreature)
-		c.Move(broadcast, x, y)
-		c.Next(broadcast, 0, 0)
-	} else {
-		g.rooms.Enter(broadcast, x, y, igo)
-		igo.SetPosition(broadcast, x, y)
-	}
-}
-
-func (g *Game) randomFreePlace() (int, int) {
-	x, y := 0, 0
-
-	for {
-		x = z.RandomNumber(0, g.config.WorldWidth-1)
-		y = z.RandomNumber(0, g.config.WorldHeight-1)
-
-		if !g.rooms.HasRoomForTwo(x, y) {
-			continue
-		}
-
-		break
-	}
-
-	return x, y
-}
-
-func (g *Game) Play() {
-	go func() {
-		defer g.recover()
-
-		for {
-			if g.config.Multiplayer {
-				g.clearWorld(g.config.Server)
-			} else {
-				g.clearWorld(false)
-			}
-
-			time.Sleep(1000 * time.Millisecond)
-		}
-	}()
-}
-
-func (g *Game) Display() {
-	defer g.recover()
-
-	if g.display {
-		n := g.config.NumTreasures
-		t := g.treasures.Len()
-		h := 0
-		s := 0
-
-		if g.player != nil {
-			h = g.player.GetHealth()
-			s = g.player.GetStrength()
-		}
-
-		go g.canvas.Draw(h, s, n-t, n)
-	}
-}
-
-func (g *Game) MoveKey(x int, y int) {
-	defer g.recover()
-
-	if g.paused {
-		return
-	}
-
-	go g.player.Next(g.config.Multiplayer, x, y)
-}
-
-func (g *Game) MoveMouse(x int, y int) {
-	defer g.recover()
-
-	if g.paused {
-		return
-	}
-
-	nextX, nextY := z.MouseToRelative(g.player, x, y)
-
-	go g.player.Next(g.config.Multiplayer, nextX, nextY)
-}
-
-func (g *Game) Fire() {
-	defer g.recover()
-
-	if g.paused || g.player.GetStrength() <= 0 {
-		return
-	}
-
-	g.player.ChangeStrength(g.config.Multiplayer, z.STRENGTH_LOST)
-
-	id := z.UUID()
-	x, y := g.player.GetPosition()
-	nextX, nextY := g.player.GetNext()
-
-	g.newMissle(g.config.Multiplayer, g.config.Name, id, x, y, nextX, nextY)
-	g.igo(g.config.Multiplayer, "Start", "Missle", id, []string{})
-
-	if g.config.Multiplayer && !g.config.Server {
-		msg := g.Event("Run")
-		msg.Class = "Missle"
-		msg.ID = id
-		g.broadcast <- msg
-	} else {
-		g.igo(g.config.Multiplayer, "Run", "Missle", id, []string{})
-	}
-}
-
-func (g *Game) Pause() {
-	paused := !g.paused
-
-	if paused {
-		msg := g.Event("Announce")
-		msg.Params["Status"] = "Pausing game"
-		msg.Params["Color"] = fmt.Sprintf("%d", z.BoldColorWhite)
-		g.broadcast <- msg
-	} else {
-		msg := g.Event("Announce")
-		msg.Params["Status"] = "Resuming game"
-		msg.Params["Color"] = fmt.Sprintf("%d", z.BoldColorWhite)
-		g.broadcast <- msg
-	}
-
-	if g.config.Multiplayer {
-		if g.config.Server {
-			g.pause(false, paused)
-		}
-	} else {
-		g.pause(false, paused)
-	}
-}
-
-func (g *Game) pause(broadcast bool, state bool) {
-	defer g.recover()
-
-	if state {
-		g.paused = true
-		g.stopCreatures(broadcast)
-	} else {
-		g.paused = false
-		g.startCreatures(broadcast)
-	}
-}
-
-func (g *Game) sfx(broadcast bool, effect string) {
-	defer g.recover()
-
-	if broadcast {
-		msg := g.Event("Sfx")
-		msg.Params["Effect"] = effect
-		g.broadcast <- msg
-	}
-
-	go g.music.Play(effect)
-}
-
-func (g *Game) recover() {
-	mode := "Game: "
-
-	if g.config.Multiplayer {
-		if g.config.Server {
-			mode += "Server: "
-		} else {
-			mode += "Client: "
-		}
-	}
-
-	if r := recover(); r != nil {
-		e, ok := r.(error)
-
-		if !ok {
-			e = fmt.Errorf("%v", r)
-			e = errors.New(mode + "Recover(): " + e.Error())
-			z.LogPanic(e)
-		} else {
-			e = errors.New(mode + "Recover(): " + e.Error())
-			z.LogPanic(e)
-		}
-	}
-}
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	tb "github.com/nsf/termbox-go"
+
+	zc "./canvas"
+	z "./common"
+	zgo "./gameobjects"
+	zm "./music"
+	zn "./networking"
+)
+
+type Game struct {
+	sync.RWMutex
+
+	config      *z.Config
+	id          string
+	session     string
+	broadcast   chan *z.Message
+	gameManager *GameManager
+	announcer   *zn.Announcer
+	referee     *Referee
+	sight       *Sight
+
+	rooms  *Rooms
+	player z.IPlayer
+	held   time.Time
+	lobby  *Lobby
+	phase  string
+	camera string
+
+	players   *GameObjectMap
+	monsters  *GameObjectMap
+	bombs     *GameObjectMap
+	portals   *GameObjectMap
+	missles   *GameObjectMap
+	healths   *GameObjectMap
+	strengths *GameObjectMap
+	treasures *GameObjectMap
+
+	statuses z.IRing
+	chats    *Chat
+	canvas   *zc.Canvas
+	theme    *zc.Theme
+	music    *zm.Music
+
+	recorder *Recorder
+	playback *Playback
+
+	display     bool
+	paused      bool
+	scoreboard  bool
+	diagnostics bool
+	minimap     bool
+
+	round     int
+	contested bool
+	finished  bool
+}
+
+func NewGame(config *z.Config) *Game {
+	return &Game{
+		paused:  true,
+		config:  config,
+		id:      z.UUID(),
+		session: z.UUID(),
+		lobby:   NewLobby(),
+		chats:   NewChat(),
+		referee: NewReferee(),
+		sight:   NewSight(),
+		phase:   z.PHASE_PLAYING,
+		round:   1,
+		minimap: config.Minimap,
+	}
+}
+
+func (g *Game) Event(action string) *z.Message {
+	m := z.NewMessage("Game", g.id, action)
+
+	return m
+}
+
+func (g *Game) Start() error {
+	defer g.recover()
+
+	g.config.Init()
+
+	if g.config.Server && g.config.Match == "" {
+		g.config.Match = z.MATCH_NAME
+	}
+
+	if e := g.init(); e != nil {
+		return e
+	}
+
+	if g.config.Load != "" && !g.config.Multiplayer {
+		return g.loadGame(g.config.Load)
+	}
+
+	g.initMultiplayer()
+
+	return nil
+}
+
+func (g *Game) init() error {
+	g.broadcast = make(chan *z.Message, 1024)
+
+	if g.config.Seed != 0 {
+		z.Seed(g.config.Seed)
+	}
+
+	if g.config.Impair != "" {
+		i, e := zn.ParseImpairment(g.config.Impair)
+
+		if e != nil {
+			return e
+		}
+
+		zn.Simulate(i)
+	}
+
+	g.gameManager = NewGameManager(g)
+
+	return g.gameManager.Run()
+}
+
+func (g *Game) initMultiplayer() {
+	if g.config.Multiplayer {
+		if g.config.Server {
+			g.display = !g.config.Headless
+
+			if g.config.Lobby {
+				g.phase = z.PHASE_LOBBY
+			}
+
+			g.initGame()
+
+			g.runCreatures(false, nil)
+
+			if g.config.Fog {
+				g.watchSight()
+			}
+
+			if g.config.Headless {
+				g.announce(false, "Dedicated server", z.BoldColorWhite)
+				g.watchRounds()
+			}
+
+			g.announce(false, "Multiplayer mode", z.BoldColorWhite)
+			g.announce(false, "Hosting game", z.BoldColorWhite)
+			g.announce(false, "Match: "+g.config.Match, z.BoldColorWhite)
+			g.announce(false, "Port: "+g.config.Port, z.BoldColorWhite)
+			g.announce(false, "IP: "+g.config.Host, z.BoldColorWhite)
+
+			if g.config.Bind != "" {
+				g.announce(false, "Bind: "+g.config.Bind, z.BoldColorWhite)
+			}
+
+			if g.config.TLS {
+				g.announceFingerprint()
+			}
+
+			if g.config.Discovery {
+				g.announceLAN()
+			}
+
+			g.record()
+
+			g.joinTeam(g.config.Team)
+
+			if g.config.Lobby {
+				g.openLobby()
+			} else if g.config.Headless {
+				g.announce(false, "Match running", z.BoldColorWhite)
+				g.pause(false, false)
+			} else {
+				g.announce(false, "Waiting for others", z.BoldColorWhite)
+				g.announce(false, "Press enter to begin", z.BoldColorWhite)
+			}
+		} else {
+			g.client()
+		}
+	} else {
+		g.display = true
+
+		g.initGame()
+
+		g.announce(false, "Single player mode", z.BoldColorWhite)
+
+		g.runCreatures(false, nil)
+
+		g.watchAutosave()
+
+		g.record()
+
+		g.pause(false, false)
+	}
+}
+
+func (g *Game) announce(broadcast bool, text string, color tb.Attribute) {
+	if broadcast {
+		msg := g.Event("Announce")
+		msg.Params["Status"] = text
+		msg.Params["Color"] = fmt.Sprintf("%d", color)
+		g.broadcast <- msg
+	}
+
+	if g.config.Headless && text != "" {
+		log.Println("Game: " + g.config.Match + ": " + text)
+	}
+
+	status := &z.Status{Text: text, Color: color}
+
+	go g.statuses.Enqueue(status)
+}
+
+func (g *Game) announceFingerprint() {
+	g.announce(false, "TLS fingerprint:", z.BoldColorGreen)
+
+	fingerprint := g.gameManager.networkManager.Fingerprint()
+
+	for len(fingerprint) > z.STATUS_LEN {
+		i := z.STATUS_LEN - z.STATUS_LEN%3
+
+		g.announce(false, fingerprint[:i], z.BoldColorGreen)
+		fingerprint = fingerprint[i:]
+	}
+
+	g.announce(false, fingerprint, z.BoldColorGreen)
+}
+
+func (g *Game) initGame() {
+	g.createWorld()
+
+	g.populateWorld()
+}
+
+func (g *Game) populateWorld() {
+	if !g.config.Headless {
+		g.initPlayer()
+	}
+
+	g.initHealths()
+
+	g.initStrengths()
+
+	g.initTreasures()
+
+	g.initBombs()
+
+	g.initPortals()
+
+	g.initMonsters()
+}
+
+func (g *Game) createWorld() {
+	g.statuses = z.NewRing()
+	g.statuses.SetCapacity(g.config.NumMsgsDisplay)
+
+	for i := 0; i < g.config.NumMsgsDisplay; i++ {
+		g.announce(false, "", z.BoldColorWhite)
+	}
+
+	if !g.config.Headless && g.config.Volume > 0 {
+		g.music = zm.NewMusic(g.config.Volume)
+		g.music.Run()
+		g.music.Background()
+	}
+
+	g.announce(false, "Creating world", z.BoldColorWhite)
+
+	g.resetWorld()
+
+	if !g.config.Headless {
+		g.canvas = zc.NewCanvas(g.config, g.rooms, g.statuses, g.chats, g.theme)
+
+		g.fitStatuses()
+	}
+}
+
+func (g *Game) resetWorld() {
+	g.rooms = NewRooms(g.session, g.broadcast, g.config.Capacity, g.config.WorldWidth, g.config.WorldHeight)
+
+	if g.canvas != nil {
+		g.canvas.SetRooms(g.rooms)
+	}
+
+	g.players = NewGameObjectMap()
+	g.healths = NewGameObjectMap()
+	g.strengths = NewGameObjectMap()
+	g.treasures = NewGameObjectMap()
+	g.bombs = NewGameObjectMap()
+	g.portals = NewGameObjectMap()
+	g.monsters = NewGameObjectMap()
+	g.missles = NewGameObjectMap()
+}
+
+func (g *Game) initPlayer() {
+	g.announce(false, "Initializing player", z.BoldColorWhite)
+
+	id := z.UUID()
+	g.newPlayer(false, g.config.Name, id, false, g.config.Color)
+	igo, _ := g.players.Get(id)
+	g.player = igo.(*zgo.Player)
+	g.player.SetMovement(false, g.config.Movement, g.config.Diagonal)
+
+	x, y := g.config.WorldWidth/2, g.config.WorldHeight/2
+	g.player.Move(false, x, y)
+	g.player.Next(false, 0, 0)
+}
+
+func (g *Game) initHealths() {
+	s := fmt.Sprintf("Initializing %d healths", g.config.NumHealths)
+	g.announce(false, s, z.BoldColorWhite)
+
+	for i := 0; i < g.config.NumHealths; i++ {
+		health := zgo.NewHealth(g.broadcast)
+		g.healths.Set(health.GetID(), health)
+		g.placeRandomly(false, health)
+	}
+}
+
+func (g *Game) initStrengths() {
+	s := fmt.Sprintf("Initializing %d strengths", g.config.NumStrengths)
+	g.announce(false, s, z.BoldColorWhite)
+
+	for i := 0; i < g.config.NumStrengths; i++ {
+		strength := zgo.NewStrength(g.broadcast)
+		g.strengths.Set(strength.GetID(), strength)
+		g.placeRandomly(false, strength)
+	}
+}
+
+func (g *Game) initTreasures() {
+	s := fmt.Sprintf("Initializing %d treasures", g.config.NumTreasures)
+	g.announce(false, s, z.BoldColorWhite)
+
+	for i := 0; i < g.config.NumTreasures; i++ {
+		treasure := zgo.NewTreasure(g.broadcast)
+		g.treasures.Set(treasure.GetID(), treasure)
+		g.placeRandomly(false, treasure)
+	}
+}
+
+func (g *Game) initBombs() {
+	s := fmt.Sprintf("Initializing %d bombs", g.config.NumBombs)
+	g.announce(false, s, z.BoldColorWhite)
+
+	for i := 0; i < g.config.NumBombs; i++ {
+		bomb := zgo.NewBomb(g.broadcast, g.rooms)
+		g.bombs.Set(bomb.GetID(), bomb)
+		g.placeRandomly(false, bomb)
+	}
+}
+
+func (g *Game) initPortals() {
+	s := fmt.Sprintf("Initializing %d portals", g.config.NumPortals)
+	g.announce(false, s, z.BoldColorWhite)
+
+	for i := 0; i < g.config.NumPortals; i++ {
+		portal := zgo.NewPortal(g.broadcast, g.config.WorldWidth, g.config.WorldHeight, g.rooms)
+		g.portals.Set(portal.GetID(), portal)
+		g.placeRandomly(false, portal)
+	}
+}
+
+func (g *Game) initMonsters() {
+	s := fmt.Sprintf("Initializing %d monsters", g.config.NumMonsters)
+	g.announce(false, s, z.BoldColorWhite)
+
+	for i := 0; i < g.config.NumMonsters; i++ {
+		monster := zgo.NewMonster(g.broadcast, g.config.WorldWidth, g.config.WorldHeight, g.rooms, g.players, g.config.Difficulty)
+		g.monsters.Set(monster.GetID(), monster)
+		g.placeRandomly(false, monster)
+	}
+}
+
+func (g *Game) placeRandomly(broadcast bool, igo z.IGameObject) {
+	class := igo.GetClass()
+	x, y := g.randomFreePlace()
+
+	if class == "Player" || class == "Monster" {
+		c, _ := igo.(z.ICreature)
+		c.Move(broadcast, x, y)
+		c.Next(broadcast, 0, 0)
+	} else {
+		g.rooms.Enter(broadcast, x, y, igo)
+		igo.SetPosition(broadcast, x, y)
+	}
+}
+
+func (g *Game) randomFreePlace() (int, int) {
+	x, y := 0, 0
+
+	for {
+		x = z.RandomNumber(0, g.config.WorldWidth-1)
+		y = z.RandomNumber(0, g.config.WorldHeight-1)
+
+		if !g.rooms.HasRoomForTwo(x, y) {
+			continue
+		}
+
+		break
+	}
+
+	return x, y
+}
+
+func (g *Game) Play() {
+	go func() {
+		defer g.recover()
+
+		for !g.Finished() {
+			if g.config.Multiplayer {
+				g.clearWorld(g.config.Server)
+			} else {
+				g.clearWorld(false)
+			}
+
+			time.Sleep(1000 * time.Millisecond)
+		}
+	}()
+}
+
+func (g *Game) Display() {
+	defer g.recover()
+
+	if g.display {
+		n := g.config.NumTreasures
+		t := g.treasures.Len()
+		h := 0
+		s := 0
+		id := ""
+
+		if c := g.followed(); c != nil {
+			h = c.GetHealth()
+			s = c.GetStrength()
+
+			g.canvas.Center(c.GetPosition())
+
+			if c != g.player {
+				id = c.GetID()
+			}
+		}
+
+		g.canvas.Follow(id)
+
+		if g.scoreboard {
+			g.canvas.Scoreboard(g.scores())
+		} else {
+			g.canvas.Scoreboard(nil)
+		}
+
+		if g.diagnostics {
+			g.canvas.Diagnostics(append(g.renderDiagnostics(), g.networkDiagnostics()...))
+		} else {
+			g.canvas.Diagnostics(nil)
+		}
+
+		g.canvas.Minimap(g.minimap)
+
+		g.canvas.Draw(h, s, n-t, n)
+	}
+}
+
+func (g *Game) ToggleMinimap() {
+	defer g.recover()
+
+	g.minimap = !g.minimap
+}
+
+func (g *Game) SetTheme(theme *zc.Theme) {
+	g.theme = theme
+}
+
+func (g *Game) Help(lines []string) {
+	if g.canvas != nil {
+		g.canvas.Help(lines)
+	}
+}
+
+func (g *Game) Resize(width, height int) {
+	defer g.recover()
+
+	if g.canvas == nil {
+		return
+	}
+
+	g.canvas.Resize(width, height)
+
+	g.fitStatuses()
+}
+
+func (g *Game) fitStatuses() {
+	if n := g.canvas.Messages(); n > g.statuses.Capacity() {
+		g.statuses.SetCapacity(n)
+	}
+}
+
+func (g *Game) MoveKey(x int, y int) {
+	defer g.recover()
+
+	if g.paused || g.player == nil {
+		return
+	}
+
+	movement, diagonal := g.player.GetMovement()
+
+	if x != 0 && y != 0 && !diagonal {
+		return
+	}
+
+	switch movement {
+	case z.MOVE_WALK:
+		if nextX, nextY := g.player.GetNext(); nextX == x && nextY == y {
+			return
+		}
+
+	case z.MOVE_HOLD:
+		if nextX, nextY := g.player.GetNext(); nextX == x && nextY == y && time.Since(g.held) < z.MOVE_HOLD_REPEAT {
+			return
+		}
+
+		g.held = time.Now()
+	}
+
+	go g.player.Next(g.config.Multiplayer, x, y)
+}
+
+func (g *Game) MoveMouse(x int, y int) {
+	defer g.recover()
+
+	if g.paused || g.player == nil {
+		return
+	}
+
+	x, y, ok := g.canvas.ToWorld(x, y)
+
+	if !ok {
+		return
+	}
+
+	_, diagonal := g.player.GetMovement()
+	nextX, nextY := z.MouseToRelative(g.player, x, y, diagonal)
+
+	go g.player.Next(g.config.Multiplayer, nextX, nextY)
+}
+
+func (g *Game) Fire() {
+	defer g.recover()
+
+	if g.paused || g.player == nil || g.player.GetStrength() <= 0 {
+		return
+	}
+
+	g.player.ChangeStrength(g.config.Multiplayer, z.STRENGTH_LOST)
+
+	id := z.UUID()
+	x, y := g.player.GetPosition()
+	nextX, nextY := g.player.GetNext()
+
+	g.newMissle(g.config.Multiplayer, g.config.Name, id, x, y, nextX, nextY)
+	g.igo(g.config.Multiplayer, "Start", "Missle", id, []string{})
+
+	if g.config.Multiplayer && !g.config.Server {
+		msg := g.Event("Run")
+		msg.Class = "Missle"
+		msg.ID = id
+		g.broadcast <- msg
+	} else {
+		g.igo(g.config.Multiplayer, "Run", "Missle", id, []string{})
+	}
+}
+
+func (g *Game) Pause() {
+	switch g.phase {
+	case z.PHASE_LOBBY:
+		g.toggleReady()
+
+		return
+
+	case z.PHASE_SPECTATING:
+		g.dropIn()
+
+		return
+	}
+
+	paused := !g.paused
+
+	if paused {
+		msg := g.Event("Announce")
+		msg.Params["Status"] = "Pausing game"
+		msg.Params["Color"] = fmt.Sprintf("%d", z.BoldColorWhite)
+		g.broadcast <- msg
+	} else {
+		msg := g.Event("Announce")
+		msg.Params["Status"] = "Resuming game"
+		msg.Params["Color"] = fmt.Sprintf("%d", z.BoldColorWhite)
+		g.broadcast <- msg
+	}
+
+	if g.config.Multiplayer {
+		if g.config.Server {
+			g.pause(false, paused)
+		}
+	} else {
+		g.pause(false, paused)
+	}
+}
+
+func (g *Game) pause(broadcast bool, state bool) {
+	defer g.recover()
+
+	if state {
+		g.paused = true
+		g.stopCreatures(broadcast)
+	} else {
+		g.paused = false
+		g.startCreatures(broadcast)
+	}
+}
+
+func (g *Game) sfx(broadcast bool, effect string) {
+	defer g.recover()
+
+	if broadcast {
+		msg := g.Event("Sfx")
+		msg.Params["Effect"] = effect
+		g.broadcast <- msg
+	}
+
+	if g.music == nil {
+		return
+	}
+
+	go g.music.Play(effect)
+}
+
+func (g *Game) recover() {
+	mode := "Game: "
+
+	if g.config.Multiplayer {
+		if g.config.Server {
+			mode += "Server: "
+		} else {
+			mode += "Client: "
+		}
+	}
+
+	if r := recover(); r != nil {
+		e, ok := r.(error)
+
+		if !ok {
+			e = fmt.Errorf("%v", r)
+			e = errors.New(mode + "Recover(): " + e.Error())
+			z.LogPanic(e)
+		} else {
+			e = errors.New(mode + "Recover(): " + e.Error())
+			z.LogPanic(e)
+		}
+	}
+}
@@ -1,310 +1,458 @@
-/*
-Zahhak2, a Golang console game.
-Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
-This program is free software: you can redistribute it and/or modify
-it under the terms of the GNU General Public License as published by
-the Free Software Foundation, either version 3 of the License, or
-(at your option) any later version.
-This program is distributed in the hope that it will be useful,
-but WITHOUT ANY WARRANTY; without even the implied warranty of
-MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
-GNU General Public License for more details.
-You should have received a copy of the GNU General Public License
-along with this program.  If not, see <http://www.gnu.org/licenses/>.
-*/
-
-package main
-
-import (
-	"errors"
-	"fmt"
-	"strconv"
-	"time"
-
-	tb "github.com/nsf/termbox-go"
-
-	z "./common"
-)
-
-type GameManager struct {
-	mode         string
-	eventManager *z.EventManager
-
-	multiplayer    bool
-	server         bool
-	gameID         string
-	session        string
-	broadcast      chan *z.Message
-	host           string
-	port           string
-	networkManager *NetworkManager
-}
-
-func NewGameManager(g *Game) *GameManager {
-	multiplayer := g.config.Multiplayer
-	server := g.config.Server
-	mode := "GameManager: "
-
-	if multiplayer {
-		if server {
-			mode += "Server: "
-		} else {
-			mode += "Client: "
-		}
-	}
-
-	eventManager := z.NewEventManager()
-
-	gameID := g.id
-	session := g.session
-	broadcast := g.broadcast
-	host := g.config.Host
-	port := g.config.Port
-
-	eventManager.On("Announce", g.announce)
-	eventManager.On("Sfx", g.sfx)
-	eventManager.On("Pause", g.pause)
-	eventManager.On("NewClient", g.newClient)
-	eventManager.On("NewPlayer", g.newPlayer)
-	eventManager.On("IGO", g.igo)
-
-	return &GameManager{
-		mode:         mode,
-		eventManager: eventManager,
-
-		multiplayer: multiplayer,
-		server:      server,
-		gameID:      gameID,
-		session:     session,
-		broadcast:   broadcast,
-		host:        host,
-		port:        port,
-	}
-}
-
-func (gm *GameManager) Run() {
-	if gm.multiplayer {
-		gm.networkManager = NewNetworkManager(gm.gameID, gm.session, gm.eventManager, gm.host, gm.port, gm.server)
-		gm.networkManager.Run()
-	}
-
-	go func() {
-		defer gm.recover()
-
-		var e error
-		m := &z.Message{}
-		ok := true
-
-		for {
-			m, ok = <-gm.broadcast
-
-			if !ok {
-				e = errors.New(gm.mode + "Outgoing: channel error")
-				gm.error(e)
-
-				time.Sleep(1 * time.Second)
-
-				continue
-			}
-
-			if gm.multiplayer {
-				m.Params["Session"] = gm.session
-				m.Params["GameID"] = gm.gameID
-
-				gm.send(m)
-			} else {
-				action := m.Action
-
-				switch action {
-				case "Error":
-					status := m.Params["Exception"]
-					color := z.BoldColorRed
-
-					gm.eventManager.Fire("Announce", false, status, color)
-
-				case "Announce":
-					status := m.Params["Status"]
-					s := m.Params["Color"]
-					i, _ := strconv.Atoi(s)
-					color := tb.Attribute(i)
-
-					gm.eventManager.Fire("Announce", false, status, color)
-
-				case "Sfx":
-					effect := m.Params["Effect"]
-
-					gm.eventManager.Fire("Sfx", false, effect)
-
-				default:
-				}
-			}
-		}
-	}()
-
-	go func() {
-		defer gm.recover()
-
-		if !gm.multiplayer {
-			return
-		}
-
-		for {
-			m, ok := <-gm.networkManager.Messages
-
-			if !ok {
-				e := errors.New(gm.mode + "Incoming: channel error")
-				gm.error(e)
-
-				time.Sleep(1 * time.Second)
-
-				continue
-			}
-
-			session := m.Params["Session"]
-			gameID := m.Params["GameID"]
-			action := m.Action
-
-			if session != gm.session {
-				continue
-			}
-
-			if gm.server {
-				if action != "Error" {
-					gm.send(m)
-				}
-			}
-
-			switch action {
-			case "Error":
-				status := m.Params["Exception"]
-				color := z.BoldColorRed
-
-				gm.eventManager.Fire("Announce", false, status, color)
-
-			case "NewPlayer":
-				name := m.Params["Name"]
-				id := m.Params["ID"]
-
-				gm.eventManager.Fire("NewPlayer", false, name, id, true)
-
-			case "Start", "Stop", "Delete", "Stay", "Release":
-				class := m.Class
-				id := m.ID
-
-				gm.eventManager.Fire("IGO", false, action, class, id, []string{})
-
-			case "SetName", "SetID":
-				class := m.Class
-				id := m.ID
-				prop := action[3:]
-				val := m.Params[prop]
-
-				gm.eventManager.Fire("IGO", false, action, class, id, []string{val})
-
-			case "ChangeHealth", "ChangeStrength", "ChangeTreasure":
-				class := m.Class
-				id := m.ID
-				points := m.Params["Points"]
-
-				gm.eventManager.Fire("IGO", false, action, class, id, []string{points})
-
-			case "Next", "SetPosition":
-				class := m.Class
-				id := m.ID
-				x := m.Params["X"]
-				y := m.Params["Y"]
-
-				gm.eventManager.Fire("IGO", false, action, class, id, []string{x, y})
-
-			case "Enter", "Leave":
-				class := m.Params["Class"]
-				id := m.Params["ID"]
-				x := m.Params["X"]
-				y := m.Params["Y"]
-
-				gm.eventManager.Fire("IGO", false, action, class, id, []string{x, y})
-
-			default:
-			}
-
-			if gm.server {
-				switch action {
-				case "Run":
-					class := m.Class
-					id := m.ID
-
-					gm.eventManager.Fire("IGO", false, action, class, id, []string{})
-
-				default:
-				}
-			}
-
-			if gm.server && gm.gameID == gameID {
-				continue
-			} else {
-				switch action {
-				case "Announce":
-					status := m.Params["Status"]
-					s := m.Params["Color"]
-					i, _ := strconv.Atoi(s)
-					color := tb.Attribute(i)
-
-					gm.eventManager.Fire("Announce", false, status, color)
-
-				case "Sfx":
-					effect := m.Params["Effect"]
-
-					gm.eventManager.Fire("Sfx", false, effect)
-
-				case "Pause":
-					s := m.Params["State"]
-					state := true
-
-					if s == "False" {
-						state = false
-					}
-					gm.eventManager.Fire("Pause", false, state)
-
-				default:
-				}
-			}
-		}
-	}()
-}
-
-func (gm *GameManager) send(message *z.Message) {
-	e := gm.networkManager.Send(message)
-
-	if e != nil {
-		if gm.server && e.Error() == "No connection" {
-			return
-		}
-
-		z.LogError(errors.New(gm.mode + "Send(): " + e.Error()))
-		gm.error(e)
-	}
-}
-
-func (gm *GameManager) SetSession(session string) {
-	gm.session = session
-	gm.networkManager.session = session
-}
-
-func (gm *GameManager) error(e error) {
-	gm.eventManager.Fire("Announce", false, e.Error(), z.BoldColorRed)
-}
-
-func (gm *GameManager) recover() {
-	if r := recover(); r != nil {
-		e, ok := r.(error)
-
-		if !ok {
-			e = fmt.Errorf("%v", r)
-			e = errors.New(gm.mode + "Recover(): " + e.Error())
-			z.LogPanic(e)
-		} else {
-			e = errors.New(gm.mode + "Recover(): " + e.Error())
-			z.LogPanic(e)
-		}
-	}
-}
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	tb "github.com/nsf/termbox-go"
+
+	z "./common"
+	zn "./networking"
+)
+
+type GameManager struct {
+	mode         string
+	eventManager *z.EventManager
+
+	multiplayer    bool
+	server         bool
+	spectator      bool
+	gameID         string
+	session        string
+	broadcast      chan *z.Message
+	host           string
+	bind           string
+	port           string
+	match          string
+	identity       zn.Credentials
+	security       zn.Security
+	networkManager *NetworkManager
+	recorder       *Recorder
+}
+
+func NewGameManager(g *Game) *GameManager {
+	multiplayer := g.config.Multiplayer
+	server := g.config.Server
+	spectator := g.config.Spectator
+	mode := "GameManager: "
+
+	if multiplayer {
+		if server {
+			mode += "Server: "
+		} else {
+			mode += "Client: "
+		}
+	}
+
+	eventManager := z.NewEventManager()
+
+	gameID := g.id
+	session := g.session
+	broadcast := g.broadcast
+	host := g.config.Host
+	bind := g.config.Bind
+	port := g.config.Port
+	match := g.config.Match
+	identity := zn.Credentials{Name: g.config.Name, Password: g.config.Password, Token: g.config.Token}
+	security := zn.Security{TLS: g.config.TLS, CertFile: g.config.CertFile, KeyFile: g.config.KeyFile, Fingerprint: g.config.Fingerprint}
+
+	eventManager.On("Announce", g.announce)
+	eventManager.On("Sfx", g.sfx)
+	eventManager.On("Pause", g.pause)
+	eventManager.On("NewClient", g.newClient)
+	eventManager.On("NewPlayer", g.newPlayer)
+	eventManager.On("IGO", g.igo)
+	eventManager.On("Ready", g.ready)
+	eventManager.On("Begin", g.begin)
+	eventManager.On("Chat", g.chat)
+	eventManager.On("FilterChat", g.filterChat)
+	eventManager.On("RouteChat", g.routeChat)
+	eventManager.On("Team", g.team)
+	eventManager.On("Round", g.loadRound)
+	eventManager.On("Kick", g.kick)
+	eventManager.On("Disconnect", g.disconnect)
+	eventManager.On("Snapshot", g.snapshot)
+	eventManager.On("Resync", g.resync)
+	eventManager.On("Validate", g.validate)
+	eventManager.On("Sight", g.filterSight)
+
+	return &GameManager{
+		mode:         mode,
+		eventManager: eventManager,
+
+		multiplayer: multiplayer,
+		server:      server,
+		spectator:   spectator,
+		gameID:      gameID,
+		session:     session,
+		broadcast:   broadcast,
+		host:        host,
+		bind:        bind,
+		port:        port,
+		match:       match,
+		identity:    identity,
+		security:    security,
+	}
+}
+
+func (gm *GameManager) Run() error {
+	if gm.multiplayer {
+		gm.networkManager = NewNetworkManager(gm.gameID, gm.session, gm.eventManager, gm.host, gm.bind, gm.port, gm.match, gm.identity, gm.security, gm.server, gm.spectator)
+
+		if e := gm.networkManager.Run(); e != nil {
+			return e
+		}
+	}
+
+	go func() {
+		defer gm.recover()
+
+		var e error
+		m := &z.Message{}
+		ok := true
+
+		for {
+			m, ok = <-gm.broadcast
+
+			if !ok {
+				e = errors.New(gm.mode + "Outgoing: channel error")
+				gm.error(e)
+
+				time.Sleep(1 * time.Second)
+
+				continue
+			}
+
+			if gm.multiplayer {
+				m.Params["Session"] = gm.session
+				m.Params["GameID"] = gm.gameID
+
+				gm.send(m)
+			} else {
+				action := m.Action
+
+				switch action {
+				case "Error":
+					status := m.Params["Exception"]
+					color := z.BoldColorRed
+
+					gm.eventManager.Fire("Announce", false, status, color)
+
+				case "Announce":
+					status := m.Params["Status"]
+					s := m.Params["Color"]
+					i, _ := strconv.Atoi(s)
+					color := tb.Attribute(i)
+
+					gm.eventManager.Fire("Announce", false, status, color)
+
+				case "Sfx":
+					effect := m.Params["Effect"]
+
+					gm.eventManager.Fire("Sfx", false, effect)
+
+				default:
+				}
+			}
+
+			if gm.recorder != nil && m.To == "" && (gm.server || !gm.multiplayer) {
+				gm.recorder.Record(m)
+			}
+		}
+	}()
+
+	go func() {
+		defer gm.recover()
+
+		if !gm.multiplayer {
+			return
+		}
+
+		for {
+			m, ok := <-gm.networkManager.Messages
+
+			if !ok {
+				e := errors.New(gm.mode + "Incoming: channel error")
+				gm.error(e)
+
+				time.Sleep(1 * time.Second)
+
+				continue
+			}
+
+			session := m.Params["Session"]
+			gameID := m.Params["GameID"]
+			action := m.Action
+
+			if session != gm.session {
+				continue
+			}
+
+			if gm.server {
+				p, _ := gm.eventManager.Fire("Validate", m)
+
+				if !p[0].Bool() {
+					continue
+				}
+
+				delete(m.Params, "Sender")
+				delete(m.Params, "SenderName")
+				delete(m.Params, "Spectator")
+
+				if action == "Chat" {
+					p, _ := gm.eventManager.Fire("FilterChat", gameID, m.Params["Text"])
+
+					if !p[1].Bool() {
+						continue
+					}
+
+					m.Params["Text"] = p[0].String()
+
+					gm.eventManager.Fire("RouteChat", m)
+				}
+
+				switch action {
+				case "Error", "Kick", "Disconnect", "Rejected", "Team":
+
+				default:
+					gm.send(m)
+				}
+			}
+
+			if gm.recorder != nil {
+				gm.recorder.Record(m)
+			}
+
+			gm.dispatch(m)
+		}
+	}()
+
+	return nil
+}
+
+func (gm *GameManager) dispatch(m *z.Message) {
+	gameID := m.Params["GameID"]
+	action := m.Action
+
+	switch action {
+	case "Error":
+		status := m.Params["Exception"]
+		color := z.BoldColorRed
+
+		gm.eventManager.Fire("Announce", false, status, color)
+
+	case "NewPlayer":
+		name := m.Params["Name"]
+		id := m.Params["ID"]
+		s := m.Params["Color"]
+		i, _ := strconv.Atoi(s)
+		color := tb.Attribute(i)
+
+		gm.eventManager.Fire("NewPlayer", false, name, id, true, color)
+
+	case "Chat":
+		name := m.Params["Name"]
+		s := m.Params["Color"]
+		i, _ := strconv.Atoi(s)
+		color := tb.Attribute(i)
+		scope := m.Params["Scope"]
+		to := m.Params["To"]
+		team := m.Params["Team"]
+		text := m.Params["Text"]
+
+		gm.eventManager.Fire("Chat", false, gameID, name, color, scope, to, team, text)
+
+	case "Team":
+		name := m.Params["Name"]
+		team := m.Params["Team"]
+
+		gm.eventManager.Fire("Team", false, name, team)
+
+	case "Ready":
+		id := m.Params["Player"]
+		state, _ := strconv.ParseBool(m.Params["State"])
+
+		gm.eventManager.Fire("Ready", false, id, state)
+
+	case "Start", "Stop", "Delete", "Stay", "Release":
+		class := m.Class
+		id := m.ID
+
+		gm.eventManager.Fire("IGO", false, action, class, id, []string{})
+
+	case "SetName", "SetID":
+		class := m.Class
+		id := m.ID
+		prop := action[3:]
+		val := m.Params[prop]
+
+		gm.eventManager.Fire("IGO", false, action, class, id, []string{val})
+
+	case "ChangeHealth", "ChangeStrength", "ChangeTreasure":
+		class := m.Class
+		id := m.ID
+		points := m.Params["Points"]
+
+		gm.eventManager.Fire("IGO", false, action, class, id, []string{points})
+
+	case "Next", "SetPosition":
+		class := m.Class
+		id := m.ID
+		x := m.Params["X"]
+		y := m.Params["Y"]
+
+		gm.eventManager.Fire("IGO", false, action, class, id, []string{x, y})
+
+	case "SetMovement":
+		class := m.Class
+		id := m.ID
+		movement := m.Params["Movement"]
+		diagonal := m.Params["Diagonal"]
+
+		gm.eventManager.Fire("IGO", false, action, class, id, []string{movement, diagonal})
+
+	case "Enter", "Leave":
+		class := m.Params["Class"]
+		id := m.Params["ID"]
+		x := m.Params["X"]
+		y := m.Params["Y"]
+
+		gm.eventManager.Fire("IGO", false, action, class, id, []string{x, y})
+
+	default:
+	}
+
+	if gm.server {
+		switch action {
+		case "Run":
+			class := m.Class
+			id := m.ID
+
+			gm.eventManager.Fire("IGO", false, action, class, id, []string{})
+
+		default:
+		}
+	} else {
+		switch action {
+		case "Begin":
+			gm.eventManager.Fire("Begin", false)
+
+		case "Round":
+			gm.eventManager.Fire("Round", false, m)
+
+		case "Resync":
+			gm.eventManager.Fire("Resync", false, m)
+
+		case "Kick":
+			name := m.Params["Name"]
+
+			gm.eventManager.Fire("Kick", false, name)
+
+		case "Disconnect":
+			name := m.Params["Name"]
+
+			gm.eventManager.Fire("Disconnect", false, name)
+
+		case "Rejected":
+			status := "Disconnected: " + m.Params["Reason"]
+
+			gm.eventManager.Fire("Announce", false, status, z.BoldColorRed)
+
+		default:
+		}
+	}
+
+	if gm.server && gm.gameID == gameID {
+		return
+	} else {
+		switch action {
+		case "Announce":
+			status := m.Params["Status"]
+			s := m.Params["Color"]
+			i, _ := strconv.Atoi(s)
+			color := tb.Attribute(i)
+
+			gm.eventManager.Fire("Announce", false, status, color)
+
+		case "Sfx":
+			effect := m.Params["Effect"]
+
+			gm.eventManager.Fire("Sfx", false, effect)
+
+		case "Pause":
+			s := m.Params["State"]
+			state := true
+
+			if s == "False" {
+				state = false
+			}
+			gm.eventManager.Fire("Pause", false, state)
+
+		default:
+		}
+	}
+}
+
+func (gm *GameManager) send(message *z.Message) {
+	if gm.server {
+		gm.eventManager.Fire("Sight", message)
+	}
+
+	e := gm.networkManager.Send(message)
+
+	if e != nil {
+		if gm.server && e.Error() == "No connection" {
+			return
+		}
+
+		z.LogError(errors.New(gm.mode + "Send(): " + e.Error()))
+		gm.error(e)
+	}
+}
+
+func (gm *GameManager) Close() {
+	if gm.networkManager != nil {
+		gm.networkManager.Close()
+	}
+}
+
+func (gm *GameManager) SetSession(session string) {
+	gm.session = session
+	gm.networkManager.session = session
+}
+
+func (gm *GameManager) error(e error) {
+	gm.eventManager.Fire("Announce", false, e.Error(), z.BoldColorRed)
+}
+
+func (gm *GameManager) recover() {
+	if r := recover(); r != nil {
+		e, ok := r.(error)
+
+		if !ok {
+			e = fmt.Errorf("%v", r)
+			e = errors.New(gm.mode + "Recover(): " + e.Error())
+			z.LogPanic(e)
+		} else {
+			e = errors.New(gm.mode + "Recover(): " + e.Error())
+			z.LogPanic(e)
+		}
+	}
+}
@@ -0,0 +1,344 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	tb "github.com/nsf/termbox-go"
+
+	z "./common"
+)
+
+var actions = []string{
+	"MoveUp", "MoveDown", "MoveLeft", "MoveRight", "MoveUpLeft", "MoveUpRight", "MoveDownLeft", "MoveDownRight",
+	"Fire", "Pause", "Quit", "Chat",
+	"Save", "Scoreboard", "Diagnostics", "Minimap", "ScrollUp", "ScrollDown", "CameraPrev", "CameraNext",
+}
+
+var playbackActions = []string{"SeekBack", "SeekForward", "Faster", "Slower", "Restart"}
+
+var replayActions = append([]string{
+	"Pause", "Quit", "Scoreboard", "Minimap", "ScrollUp", "ScrollDown", "CameraPrev", "CameraNext",
+}, playbackActions...)
+
+var keyNames = map[string]tb.Key{
+	"Up":        tb.KeyArrowUp,
+	"Down":      tb.KeyArrowDown,
+	"Left":      tb.KeyArrowLeft,
+	"Right":     tb.KeyArrowRight,
+	"Space":     tb.KeySpace,
+	"Enter":     tb.KeyEnter,
+	"Esc":       tb.KeyEsc,
+	"Tab":       tb.KeyTab,
+	"Backspace": tb.KeyBackspace2,
+	"Insert":    tb.KeyInsert,
+	"Delete":    tb.KeyDelete,
+	"Home":      tb.KeyHome,
+	"End":       tb.KeyEnd,
+	"PgUp":      tb.KeyPgup,
+	"PgDn":      tb.KeyPgdn,
+	"F1":        tb.KeyF1,
+	"F2":        tb.KeyF2,
+	"F3":        tb.KeyF3,
+	"F4":        tb.KeyF4,
+	"F5":        tb.KeyF5,
+	"F6":        tb.KeyF6,
+	"F7":        tb.KeyF7,
+	"F8":        tb.KeyF8,
+	"F9":        tb.KeyF9,
+	"F10":       tb.KeyF10,
+	"F11":       tb.KeyF11,
+	"F12":       tb.KeyF12,
+}
+
+var layouts = map[string]map[string][]string{
+	"arrows": {
+		"MoveUp":        {"Up"},
+		"MoveDown":      {"Down"},
+		"MoveLeft":      {"Left"},
+		"MoveRight":     {"Right"},
+		"MoveUpLeft":    {},
+		"MoveUpRight":   {},
+		"MoveDownLeft":  {},
+		"MoveDownRight": {},
+		"Fire":          {"Space"},
+		"Pause":         {"Enter"},
+		"Quit":          {"Esc", "q"},
+		"Chat":          {"t"},
+		"Save":          {"s"},
+		"Scoreboard":    {"Tab"},
+		"Diagnostics":   {"n"},
+		"Minimap":       {"m"},
+		"ScrollUp":      {"PgUp"},
+		"ScrollDown":    {"PgDn"},
+		"CameraPrev":    {"<", ","},
+		"CameraNext":    {">", "."},
+		"SeekBack":      {"Left"},
+		"SeekForward":   {"Right"},
+		"Faster":        {"Up"},
+		"Slower":        {"Down"},
+		"Restart":       {"Home"},
+	},
+	"wasd": {
+		"MoveUp":    {"w", "Up"},
+		"MoveDown":  {"s", "Down"},
+		"MoveLeft":  {"a", "Left"},
+		"MoveRight": {"d", "Right"},
+		"Save":      {"F5"},
+	},
+	"vi": {
+		"MoveUp":        {"k", "Up"},
+		"MoveDown":      {"j", "Down"},
+		"MoveLeft":      {"h", "Left"},
+		"MoveRight":     {"l", "Right"},
+		"MoveUpLeft":    {"y"},
+		"MoveUpRight":   {"u"},
+		"MoveDownLeft":  {"b"},
+		"MoveDownRight": {"n"},
+		"Diagnostics":   {"F2"},
+	},
+	"numpad": {
+		"MoveUp":        {"8", "Up"},
+		"MoveDown":      {"2", "Down"},
+		"MoveLeft":      {"4", "Left"},
+		"MoveRight":     {"6", "Right"},
+		"MoveUpLeft":    {"7"},
+		"MoveUpRight":   {"9"},
+		"MoveDownLeft":  {"1"},
+		"MoveDownRight": {"3"},
+		"Fire":          {"5", "0", "Space"},
+	},
+}
+
+type Binding struct {
+	Key tb.Key
+	Ch  rune
+}
+
+type KeymapFile struct {
+	Layout   string
+	Bindings map[string][]string
+}
+
+type Keymap struct {
+	actions  map[Binding]string
+	bindings map[string][]string
+}
+
+func LoadKeymap(name string) (*Keymap, error) {
+	file := &KeymapFile{Layout: name}
+
+	if _, ok := layouts[name]; !ok {
+		path := name
+
+		if path == "" {
+			path = filepath.Join(filepath.Dir(configPath()), z.KEYMAP_FILE)
+		}
+
+		bs, e := ioutil.ReadFile(path)
+
+		switch {
+		case e == nil:
+			file.Layout = ""
+
+			if e := json.Unmarshal(bs, file); e != nil {
+				return nil, errors.New("could not read " + path + ": " + e.Error())
+			}
+
+		case name != "" && os.IsNotExist(e):
+			return nil, errors.New("keymap " + name + " is neither a layout (" + strings.Join(layoutNames(), ", ") + ") nor a file")
+
+		case !os.IsNotExist(e):
+			return nil, e
+		}
+	}
+
+	return NewKeymap(file)
+}
+
+func NewKeymap(file *KeymapFile) (*Keymap, error) {
+	bindings := map[string][]string{}
+
+	for action, keys := range layouts["arrows"] {
+		bindings[action] = keys
+	}
+
+	if file.Layout != "" {
+		layout, ok := layouts[file.Layout]
+
+		if !ok {
+			return nil, errors.New("unknown key layout " + file.Layout + ", layouts are " + strings.Join(layoutNames(), ", "))
+		}
+
+		for action, keys := range layout {
+			bindings[action] = keys
+		}
+	}
+
+	for action, keys := range file.Bindings {
+		if _, ok := bindings[action]; !ok {
+			return nil, errors.New("unknown action " + action + ", actions are " + strings.Join(actions, ", ") + ", " + strings.Join(playbackActions, ", "))
+		}
+
+		bindings[action] = keys
+	}
+
+	return newKeymap(actions, bindings)
+}
+
+func (k *Keymap) Replay() (*Keymap, error) {
+	return newKeymap(replayActions, k.bindings)
+}
+
+func newKeymap(actions []string, bindings map[string][]string) (*Keymap, error) {
+	k := &Keymap{actions: map[Binding]string{}, bindings: bindings}
+
+	for _, action := range actions {
+		for _, name := range bindings[action] {
+			bs, e := parseKey(name)
+
+			if e != nil {
+				return nil, errors.New(action + ": " + e.Error())
+			}
+
+			for _, b := range bs {
+				if other, ok := k.actions[b]; ok && other != action {
+					return nil, fmt.Errorf("key %s is bound to both %s and %s", name, other, action)
+				}
+
+				k.actions[b] = action
+			}
+		}
+	}
+
+	return k, nil
+}
+
+func layoutNames() []string {
+	names := []string{}
+
+	for name := range layouts {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func parseKey(name string) ([]Binding, error) {
+	if name == "Backspace" {
+		return []Binding{{Key: tb.KeyBackspace}, {Key: tb.KeyBackspace2}}, nil
+	}
+
+	if key, ok := keyNames[name]; ok {
+		return []Binding{{Key: key}}, nil
+	}
+
+	runes := []rune(name)
+
+	if len(runes) != 1 || !unicode.IsPrint(runes[0]) || runes[0] == ' ' {
+		return nil, errors.New("unknown key " + name + ", use a single character or one of " + strings.Join(namedKeys(), ", "))
+	}
+
+	r := runes[0]
+
+	if unicode.IsLetter(r) {
+		return []Binding{{Ch: unicode.ToLower(r)}, {Ch: unicode.ToUpper(r)}}, nil
+	}
+
+	return []Binding{{Ch: r}}, nil
+}
+
+func namedKeys() []string {
+	names := []string{}
+
+	for name := range keyNames {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (k *Keymap) Action(ev tb.Event) string {
+	if ev.Ch != 0 {
+		return k.actions[Binding{Ch: ev.Ch}]
+	}
+
+	return k.actions[Binding{Key: ev.Key}]
+}
+
+func (k *Keymap) Help() []string {
+	return []string{
+		k.label("Pause") + ": Pause / " + k.label("Chat") + ": Chat",
+		k.label("Quit") + ": Quit / " + k.label("ScrollUp", "ScrollDown") + ": Log",
+		k.moves() + "/Left mouse: Move",
+		k.label("Fire") + "/Right mouse: Shoot",
+	}
+}
+
+func (k *Keymap) ReplayHelp() []string {
+	return []string{
+		k.label("Pause") + ": Pause / " + k.label("Quit") + ": Quit",
+		k.label("SeekBack", "SeekForward") + ": Seek 10s / " + k.label("Minimap") + ": Map",
+		k.label("Faster", "Slower") + ": Speed / " + k.label("Restart") + ": Start",
+		k.label("CameraPrev", "CameraNext") + ": Follow / " + k.label("Scoreboard") + ": Scores",
+	}
+}
+
+func (k *Keymap) label(actions ...string) string {
+	labels := []string{}
+
+	for _, action := range actions {
+		for _, name := range k.bindings[action] {
+			if len([]rune(name)) == 1 {
+				name = strings.ToUpper(name)
+			}
+
+			labels = append(labels, name)
+		}
+	}
+
+	return strings.Join(labels, "/")
+}
+
+func (k *Keymap) moves() string {
+	moves := []string{"MoveUp", "MoveLeft", "MoveDown", "MoveRight"}
+	keys := ""
+
+	for _, action := range moves {
+		names := k.bindings[action]
+
+		if len(names) == 0 || len([]rune(names[0])) != 1 {
+			return "Arrows"
+		}
+
+		keys += strings.ToUpper(names[0])
+	}
+
+	return keys
+}
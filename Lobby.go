@@ -0,0 +1,298 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	tb "github.com/nsf/termbox-go"
+
+	z "./common"
+	zgo "./gameobjects"
+)
+
+type LobbyMember struct {
+	ID    string
+	Name  string
+	Color tb.Attribute
+	Ready bool
+}
+
+type Lobby struct {
+	sync.RWMutex
+
+	members map[string]*LobbyMember
+}
+
+func NewLobby() *Lobby {
+	return &Lobby{
+		members: map[string]*LobbyMember{}}
+}
+
+func (l *Lobby) Join(id, name string, color tb.Attribute) {
+	l.Lock()
+	defer l.Unlock()
+
+	if m, ok := l.members[id]; ok {
+		m.Name, m.Color = name, color
+
+		return
+	}
+
+	l.members[id] = &LobbyMember{ID: id, Name: name, Color: color}
+}
+
+func (l *Lobby) Leave(id string) {
+	l.Lock()
+	defer l.Unlock()
+
+	delete(l.members, id)
+}
+
+func (l *Lobby) Clear() {
+	l.Lock()
+	defer l.Unlock()
+
+	l.members = map[string]*LobbyMember{}
+}
+
+func (l *Lobby) Get(id string) (*LobbyMember, bool) {
+	l.RLock()
+	defer l.RUnlock()
+
+	m, ok := l.members[id]
+
+	return m, ok
+}
+
+func (l *Lobby) SetReady(id string, ready bool) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	m, ok := l.members[id]
+
+	if !ok {
+		return false
+	}
+
+	m.Ready = ready
+
+	return true
+}
+
+func (l *Lobby) Count() (int, int) {
+	l.RLock()
+	defer l.RUnlock()
+
+	ready := 0
+
+	for _, m := range l.members {
+		if m.Ready {
+			ready++
+		}
+	}
+
+	return ready, len(l.members)
+}
+
+func (l *Lobby) AllReady() bool {
+	ready, total := l.Count()
+
+	return total > 0 && ready == total
+}
+
+func (l *Lobby) Members() []*LobbyMember {
+	l.RLock()
+	defer l.RUnlock()
+
+	ms := []*LobbyMember{}
+
+	for _, m := range l.members {
+		ms = append(ms, m)
+	}
+
+	sort.Slice(ms, func(i, j int) bool { return ms[i].Name < ms[j].Name })
+
+	return ms
+}
+
+func (l *Lobby) JSON() []string {
+	jsonArray := []string{}
+
+	for _, m := range l.Members() {
+		bs, _ := json.Marshal(m)
+		jsonArray = append(jsonArray, string(bs))
+	}
+
+	return jsonArray
+}
+
+func (l *Lobby) Load(mp []string) {
+	l.Lock()
+	defer l.Unlock()
+
+	for _, o := range mp {
+		m := &LobbyMember{}
+
+		if e := json.Unmarshal([]byte(o), m); e != nil {
+			continue
+		}
+
+		l.members[m.ID] = m
+	}
+}
+
+func (g *Game) openLobby() {
+	g.announce(false, "Lobby open", z.BoldColorWhite)
+	g.announceOptions()
+
+	if g.config.Headless {
+		g.announce(false, "Waiting for players to get ready", z.BoldColorWhite)
+	} else {
+		g.announce(false, "Press enter when ready", z.BoldColorWhite)
+	}
+}
+
+func (g *Game) announceOptions() {
+	c := g.config
+
+	s := fmt.Sprintf("World: %dx%d", c.WorldWidth, c.WorldHeight)
+	g.announce(false, s, z.BoldColorCyan)
+	s = fmt.Sprintf("Difficulty: %d", c.Difficulty)
+	g.announce(false, s, z.BoldColorCyan)
+	s = fmt.Sprintf("Monsters: %d Bombs: %d", c.NumMonsters, c.NumBombs)
+	g.announce(false, s, z.BoldColorCyan)
+	s = fmt.Sprintf("Treasures: %d Portals: %d", c.NumTreasures, c.NumPortals)
+	g.announce(false, s, z.BoldColorCyan)
+	s = "Late joiners: " + c.JoinMode
+	g.announce(false, s, z.BoldColorCyan)
+}
+
+func (g *Game) toggleReady() {
+	if g.player == nil {
+		return
+	}
+
+	id := g.player.GetID()
+	m, ok := g.lobby.Get(id)
+
+	if !ok {
+		return
+	}
+
+	g.ready(true, id, !m.Ready)
+}
+
+func (g *Game) ready(broadcast bool, id string, state bool) {
+	defer g.recover()
+
+	if g.phase != z.PHASE_LOBBY {
+		return
+	}
+
+	if broadcast {
+		msg := g.Event("Ready")
+		msg.Params["Player"] = id
+		msg.Params["State"] = strconv.FormatBool(state)
+		g.broadcast <- msg
+	}
+
+	if !g.lobby.SetReady(id, state) {
+		return
+	}
+
+	m, _ := g.lobby.Get(id)
+	ready, total := g.lobby.Count()
+
+	if state {
+		s := fmt.Sprintf("%s is ready (%d/%d)", m.Name, ready, total)
+		g.announce(false, s, m.Color)
+	} else {
+		s := fmt.Sprintf("%s is not ready (%d/%d)", m.Name, ready, total)
+		g.announce(false, s, m.Color)
+	}
+
+	if g.config.Server && g.lobby.AllReady() {
+		g.begin(true)
+	}
+}
+
+func (g *Game) begin(broadcast bool) {
+	defer g.recover()
+
+	if g.phase == z.PHASE_SPECTATING {
+		g.announce(false, "Match starting", z.BoldColorWhite)
+
+		return
+	}
+
+	if g.phase != z.PHASE_LOBBY {
+		return
+	}
+
+	if broadcast {
+		msg := g.Event("Begin")
+		g.broadcast <- msg
+	}
+
+	g.phase = z.PHASE_PLAYING
+
+	g.announce(false, "Match starting", z.BoldColorWhite)
+
+	g.pause(broadcast, false)
+}
+
+func (g *Game) spawnPlayer() {
+	id := z.UUID()
+	x, y := g.randomFreePlace()
+
+	g.newPlayer(true, g.config.Name, id, false, g.config.Color)
+
+	igo, _ := g.players.Get(id)
+	g.player = igo.(*zgo.Player)
+	g.player.SetMovement(true, g.config.Movement, g.config.Diagonal)
+
+	g.player.SetPosition(true, x, y)
+	g.rooms.Enter(true, x, y, igo)
+
+	msg := g.Event("Run")
+	msg.Class = "Player"
+	msg.ID = id
+	g.broadcast <- msg
+
+	g.announce(false, "Initializing player", z.BoldColorWhite)
+}
+
+func (g *Game) dropIn() {
+	defer g.recover()
+
+	if g.phase != z.PHASE_SPECTATING || g.config.Spectator {
+		return
+	}
+
+	g.spawnPlayer()
+
+	g.player.Start(true)
+
+	g.phase = z.PHASE_PLAYING
+	g.paused = false
+
+	g.announce(false, "Joined the match", z.BoldColorWhite)
+}
@@ -1,202 +1,349 @@
-/*
-Zahhak2, a Golang console game.
-Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
-This program is free software: you can redistribute it and/or modify
-it under the terms of the GNU General Public License as published by
-the Free Software Foundation, either version 3 of the License, or
-(at your option) any later version.
-This program is distributed in the hope that it will be useful,
-but WITHOUT ANY WARRANTY; without even the implied warranty of
-MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
-GNU General Public License for more details.
-You should have received a copy of the GNU General Public License
-along with this program.  If not, see <http://www.gnu.org/licenses/>.
-*/
-
-package main
-
-import (
-	"errors"
-	"fmt"
-	"log"
-	"os"
-	"runtime"
-	"sync"
-	"time"
-
-	tb "github.com/nsf/termbox-go"
-	ac "github.com/shiena/ansicolor"
-
-	z "./common"
-)
-
-var game *Game
-var quit bool
-var prevX, prevY int
-
-var muQuit = &sync.RWMutex{}
-
-func init() {
-	runtime.GOMAXPROCS(runtime.NumCPU())
-
-	f, e := os.OpenFile("zahhak2.log", os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
-
-	if e != nil {
-		panic(e)
-	}
-
-	log.SetOutput(f)
-
-	err := tb.Init()
-
-	if err != nil {
-		panic(err)
-	}
-
-	tb.SetOutputMode(tb.OutputNormal)
-	tb.SetInputMode(tb.InputEsc | tb.InputMouse)
-	tb.HideCursor()
-}
-
-func main() {
-	defer mainRecover()
-
-	quit = false
-
-	//menu := zm.NewMenu()
-	//menu.Options()
-
-	config := z.NewConfig()
-	//config.Multiplayer = menu.Multiplayer
-	//config.Server = menu.Server
-	//config.Host = menu.Host
-	//config.Port = menu.Port
-	//config.Name = menu.Name
-
-	//if !menu.Quit {
-	game = NewGame(config)
-	game.Start()
-	game.Play()
-
-	go input()
-	play()
-	//}
-
-	tb.Clear(tb.ColorDefault, tb.ColorDefault)
-	tb.Close()
-
-	w := ac.NewAnsiColorWriter(os.Stdout)
-	text := "%s%s%s" + GOODBYE + "%s%s%s\n"
-
-	fmt.Fprintf(w, text, "\x1b[31m", "\x1b[1m", "\x1b[40m", "\x1b[39m", "\x1b[49m", "\x1b[0m")
-}
-
-func play() {
-	for !quit {
-		game.Display()
-
-		time.Sleep(100 * time.Millisecond)
-	}
-}
-
-func input() {
-	defer mainRecover()
-
-	for !getQuit() {
-		switch ev := tb.PollEvent(); ev.Type {
-		case tb.EventKey:
-			switch ev.Key {
-			case tb.KeyArrowUp:
-				moveKey(0, -1)
-
-			case tb.KeyArrowDown:
-				moveKey(0, 1)
-
-			case tb.KeyArrowRight:
-				moveKey(1, 0)
-
-			case tb.KeyArrowLeft:
-				moveKey(-1, 0)
-
-			case tb.KeySpace:
-				shoot()
-
-			case tb.KeyEnter:
-				pause()
-
-			case tb.KeyEsc:
-				setQuit(true)
-
-			default:
-				ch := ev.Ch
-
-				if ch == 'q' {
-					setQuit(true)
-				}
-			}
-		case tb.EventMouse:
-			if ev.Key == tb.MouseLeft {
-				moveMouse(ev.MouseX, ev.MouseY)
-			} else if ev.Key == tb.MouseRight {
-				shoot()
-			}
-		case tb.EventInterrupt:
-			setQuit(true)
-
-			break
-		}
-
-		time.Sleep(100 * time.Millisecond)
-	}
-}
-
-func getQuit() bool {
-	muQuit.RLock()
-	defer muQuit.RUnlock()
-
-	return quit
-}
-
-func setQuit(newQuit bool) {
-	muQuit.Lock()
-	defer muQuit.Unlock()
-
-	quit = newQuit
-}
-
-func moveKey(x, y int) {
-	if x == prevX && y == prevY {
-		return
-	}
-
-	game.MoveKey(x, y)
-
-	prevX, prevY = x, y
-}
-
-func moveMouse(x, y int) {
-	game.MoveMouse(x, y)
-}
-
-func shoot() {
-	game.Fire()
-}
-
-func pause() {
-	game.Pause()
-}
-
-func mainRecover() {
-	if r := recover(); r != nil {
-		e, ok := r.(error)
-
-		if !ok {
-			e = fmt.Errorf("%v", r)
-			e = errors.New("Main: " + e.Error())
-			z.LogPanic(e)
-		} else {
-			e = errors.New("Main: " + e.Error())
-			z.LogPanic(e)
-		}
-	}
-}
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	tb "github.com/nsf/termbox-go"
+	ac "github.com/shiena/ansicolor"
+
+	z "./common"
+	zc "./canvas"
+	zm "./menu"
+)
+
+var game *Game
+var keymap *Keymap
+var theme *zc.Theme
+var quit bool
+
+var muQuit = &sync.RWMutex{}
+
+func init() {
+	runtime.GOMAXPROCS(runtime.NumCPU())
+}
+
+func initTerminal() {
+	f, e := os.OpenFile("zahhak2.log", os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+
+	if e != nil {
+		panic(e)
+	}
+
+	log.SetOutput(f)
+
+	err := tb.Init()
+
+	if err != nil {
+		panic(err)
+	}
+
+	tb.SetOutputMode(tb.OutputNormal)
+	tb.SetInputMode(tb.InputEsc | tb.InputMouse)
+	tb.HideCursor()
+}
+
+func main() {
+	defer mainRecover()
+
+	name, args := "play", os.Args[1:]
+
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	switch name {
+	case "play", "host", "join", "load":
+		if name == "load" && len(args) == 0 {
+			saves()
+
+			break
+		}
+
+		config, e := gameFlags(name, args)
+
+		if e != nil {
+			usageError(e.Error())
+		}
+
+		keymap, e = LoadKeymap(config.Keymap)
+
+		if e != nil {
+			usageError(e.Error())
+		}
+
+		theme, e = LoadTheme(config.Theme, config.Symbols)
+
+		if e != nil {
+			usageError(e.Error())
+		}
+
+		run(config)
+
+	case "server":
+		serve(args)
+
+	case "replay":
+		watch(args)
+
+	case "editor":
+		editor(args)
+
+	case "help":
+		help(args)
+
+	default:
+		usageError("unknown command " + name)
+	}
+}
+
+func run(config *z.Config) {
+	initTerminal()
+
+	log.Println("Main: settings " + printConfig(config))
+
+	quit = false
+
+	if config.Browse && !browse(config) {
+		tb.Close()
+
+		return
+	}
+
+	//menu := zm.NewMenu()
+	//menu.Options()
+
+	//config.Load = menu.Load
+	//config.Multiplayer = menu.Multiplayer
+	//config.Server = menu.Server
+	//config.Host = menu.Host
+	//config.Bind = menu.Bind
+	//config.Port = menu.Port
+	//config.Name = menu.Name
+	//config.Color = menu.Color
+	//config.Spectator = menu.Spectator
+	//config.Match = menu.Match
+	//config.Password = menu.Password
+	//config.Token = menu.Token
+	//config.TLS = menu.TLS
+	//config.Fingerprint = menu.Fingerprint
+
+	//if !menu.Quit {
+	game = NewGame(config)
+	game.SetTheme(theme)
+
+	if e := game.Start(); e != nil {
+		tb.Close()
+
+		println("Error: " + e.Error())
+
+		os.Exit(1)
+	}
+
+	game.Play()
+	game.Help(keymap.Help())
+
+	go input()
+	play(config.FPS)
+
+	game.StopRecording()
+	//}
+
+	tb.Clear(tb.ColorDefault, tb.ColorDefault)
+	tb.Close()
+
+	w := ac.NewAnsiColorWriter(os.Stdout)
+	text := "%s%s%s" + GOODBYE + "%s%s%s\n"
+
+	fmt.Fprintf(w, text, "\x1b[31m", "\x1b[1m", "\x1b[40m", "\x1b[39m", "\x1b[49m", "\x1b[0m")
+}
+
+func browse(config *z.Config) bool {
+	menu := zm.NewMenu()
+	menu.Fingerprint = config.Fingerprint
+
+	if !menu.FindGame() {
+		return false
+	}
+
+	config.Host = menu.Host
+	config.Port = menu.Port
+	config.Match = menu.Match
+	config.TLS = menu.TLS
+	config.Fingerprint = menu.Fingerprint
+
+	tb.Clear(tb.ColorDefault, tb.ColorDefault)
+
+	return true
+}
+
+func play(fps int) {
+	frame := time.Second / time.Duration(fps)
+
+	for !quit {
+		start := time.Now()
+
+		game.Display()
+
+		if elapsed := time.Since(start); elapsed < frame {
+			time.Sleep(frame - elapsed)
+		}
+	}
+}
+
+func input() {
+	defer mainRecover()
+
+	for !getQuit() {
+		switch ev := tb.PollEvent(); ev.Type {
+		case tb.EventKey:
+			if game.Chatting() {
+				game.ChatKey(ev.Key, ev.Ch)
+
+				break
+			}
+
+			switch keymap.Action(ev) {
+			case "MoveUp":
+				moveKey(0, -1)
+
+			case "MoveDown":
+				moveKey(0, 1)
+
+			case "MoveRight":
+				moveKey(1, 0)
+
+			case "MoveLeft":
+				moveKey(-1, 0)
+
+			case "MoveUpLeft":
+				moveKey(-1, -1)
+
+			case "MoveUpRight":
+				moveKey(1, -1)
+
+			case "MoveDownLeft":
+				moveKey(-1, 1)
+
+			case "MoveDownRight":
+				moveKey(1, 1)
+
+			case "Fire":
+				shoot()
+
+			case "Pause":
+				pause()
+
+			case "Quit":
+				setQuit(true)
+
+			case "ScrollUp":
+				game.ScrollChat(1)
+
+			case "ScrollDown":
+				game.ScrollChat(-1)
+
+			case "Scoreboard":
+				game.ToggleScoreboard()
+
+			case "Chat":
+				game.OpenChat()
+
+			case "Diagnostics":
+				game.ToggleDiagnostics()
+
+			case "Minimap":
+				game.ToggleMinimap()
+
+			case "Save":
+				game.OpenSave()
+
+			case "CameraPrev":
+				game.CycleCamera(-1)
+
+			case "CameraNext":
+				game.CycleCamera(1)
+			}
+		case tb.EventMouse:
+			if ev.Key == tb.MouseLeft {
+				moveMouse(ev.MouseX, ev.MouseY)
+			} else if ev.Key == tb.MouseRight {
+				shoot()
+			}
+		case tb.EventResize:
+			game.Resize(ev.Width, ev.Height)
+		case tb.EventInterrupt:
+			setQuit(true)
+
+			break
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func getQuit() bool {
+	muQuit.RLock()
+	defer muQuit.RUnlock()
+
+	return quit
+}
+
+func setQuit(newQuit bool) {
+	muQuit.Lock()
+	defer muQuit.Unlock()
+
+	quit = newQuit
+}
+
+func moveKey(x, y int) {
+	game.MoveKey(x, y)
+}
+
+func moveMouse(x, y int) {
+	game.MoveMouse(x, y)
+}
+
+func shoot() {
+	game.Fire()
+}
+
+func pause() {
+	game.Pause()
+}
+
+func mainRecover() {
+	if r := recover(); r != nil {
+		e, ok := r.(error)
+
+		if !ok {
+			e = fmt.Errorf("%v", r)
+			e = errors.New("Main: " + e.Error())
+			z.LogPanic(e)
+		} else {
+			e = errors.New("Main: " + e.Error())
+			z.LogPanic(e)
+		}
+	}
+}
@@ -1,63 +1,147 @@
-/*
-Zahhak2, a Golang console game.
-Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
-This program is free software: you can redistribute it and/or modify
-it under the terms of the GNU General Public License as published by
-the Free Software Foundation, either version 3 of the License, or
-(at your option) any later version.
-This program is distributed in the hope that it will be useful,
-but WITHOUT ANY WARRANTY; without even the implied warranty of
-MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
-GNU General Public License for more details.
-You should have received a copy of the GNU General Public License
-along with this program.  If not, see <http://www.gnu.org/licenses/>.
-*/
-
-package main
-
-import (
-	z "./common"
-	zgo "./gameobjects"
-)
-
-func (g *Game) newClient() string {
-	defer g.recover()
-
-	g.announce(true, "Pausing game", z.BoldColorWhite)
-
-	g.pause(true, true)
-
-	json := g.getCurrentState()
-
-	g.announce(true, "New client", z.BoldColorWhite)
-
-	return json
-}
-
-func (g *Game) newPlayer(broadcast bool, name, id string, opponent bool) {
-	defer g.recover()
-
-	symbol := '☻'
-	color := z.BoldColorYellow
-
-	if opponent {
-		symbol = '☺'
-		color = z.BoldColorMagenta
-	}
-
-	p := zgo.NewPlayer(broadcast, g.broadcast, g.id, g.config.WorldWidth, g.config.WorldHeight, g.rooms, name, id, symbol, color)
-
-	g.players.Set(id, p)
-
-	g.sfx(broadcast, "teleport")
-}
-
-func (g *Game) newMissle(broadcast bool, class, id string, x, y, nextX, nextY int) {
-	defer g.recover()
-
-	m := zgo.NewMissle(broadcast, g.broadcast, g.id, g.config.WorldWidth, g.config.WorldHeight, g.rooms, class, id, x, y, nextX, nextY)
-
-	g.missles.Set(id, m)
-
-	g.sfx(broadcast, "fire")
-}
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	tb "github.com/nsf/termbox-go"
+
+	z "./common"
+	zgo "./gameobjects"
+)
+
+func (g *Game) newClient(spectator bool, name, password, token string) (string, bool) {
+	defer g.recover()
+
+	if g.config.Password != "" && !sameSecret(g.config.Password, password) {
+		g.announce(false, "Rejected "+name+": wrong password", z.BoldColorWhite)
+
+		return g.rejected(z.REJECT_PASSWORD, "wrong password"), false
+	}
+
+	if !z.ValidName(name) {
+		g.announce(false, "Rejected client: invalid name", z.BoldColorWhite)
+
+		s := fmt.Sprintf("names are 1-%d letters, digits, - _ or .", z.NAME_LEN)
+
+		return g.rejected(z.REJECT_NAME, s), false
+	}
+
+	if len(g.config.Tokens) > 0 && !sameSecret(g.config.Tokens[name], token) {
+		g.announce(false, "Rejected "+name+": wrong token", z.BoldColorWhite)
+
+		return g.rejected(z.REJECT_TOKEN, "wrong or missing token for "+name), false
+	}
+
+	if g.nameTaken(name) {
+		g.announce(false, "Rejected "+name+": name taken", z.BoldColorWhite)
+
+		return g.rejected(z.REJECT_TAKEN, "name "+name+" is already taken"), false
+	}
+
+	if spectator {
+		json := g.getCurrentState(!g.config.Overview)
+
+		if g.config.Overview {
+			g.sight.Unwatch(name)
+		} else {
+			g.sight.Watch(name)
+		}
+
+		g.chats.SetTeam(name, "")
+
+		g.announce(true, "New spectator", z.BoldColorWhite)
+
+		return json, true
+	}
+
+	if g.phase != z.PHASE_LOBBY && g.config.JoinMode == z.JOIN_DISABLED {
+		g.announce(false, "Rejected new client", z.BoldColorWhite)
+
+		return g.rejected(z.REJECT_STARTED, "match already started"), false
+	}
+
+	json := g.getCurrentState(true)
+	g.sight.Watch(name)
+	g.chats.SetTeam(name, "")
+
+	g.announce(true, "New client", z.BoldColorWhite)
+
+	return json, true
+}
+
+func (g *Game) nameTaken(name string) bool {
+	for _, p := range g.players.GetValues() {
+		if !p.Deleted() && strings.EqualFold(p.GetName(), name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func opponentColor(color tb.Attribute) tb.Attribute {
+	if color == z.PLAYER_COLOR {
+		return z.OPPONENT_COLOR
+	}
+
+	return color
+}
+
+func sameSecret(expected, given string) bool {
+	if expected == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(given)) == 1
+}
+
+func (g *Game) newPlayer(broadcast bool, name, id string, opponent bool, color tb.Attribute) {
+	defer g.recover()
+
+	symbol := '☻'
+
+	if opponent {
+		symbol = '☺'
+		color = opponentColor(color)
+	}
+
+	p := zgo.NewPlayer(broadcast, g.broadcast, g.id, g.config.WorldWidth, g.config.WorldHeight, g.rooms, name, id, symbol, color)
+
+	g.players.Set(id, p)
+
+	if g.phase == z.PHASE_LOBBY {
+		g.lobby.Join(id, name, color)
+
+		s := name + " joined the lobby"
+		g.announce(false, s, color)
+	}
+
+	g.sfx(broadcast, "teleport")
+}
+
+func (g *Game) newMissle(broadcast bool, class, id string, x, y, nextX, nextY int) {
+	defer g.recover()
+
+	m := zgo.NewMissle(broadcast || g.recorder != nil, g.broadcast, g.id, g.config.WorldWidth, g.config.WorldHeight, g.rooms, class, id, x, y, nextX, nextY)
+
+	g.missles.Set(id, m)
+
+	g.sfx(broadcast, "fire")
+}
@@ -0,0 +1,251 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	tb "github.com/nsf/termbox-go"
+
+	z "./common"
+)
+
+type ConfigFile struct {
+	Profile  string
+	Profiles map[string]json.RawMessage
+}
+
+var profiles = map[string]string{
+	"easy":      `{"Difficulty": 70, "NumMonsters": 3, "NumHealths": 20, "NumStrengths": 15, "NumBombs": 5}`,
+	"arena":     `{"Dynamic": false, "WorldWidth": 30, "WorldHeight": 15, "Difficulty": 10, "NumMonsters": 15, "NumTreasures": 5, "NumPortals": 4, "Rounds": 5}`,
+	"lan-party": `{"Lobby": true, "Discovery": true, "JoinMode": "Play", "Rounds": 3}`,
+}
+
+func configFlags(fs *flag.FlagSet) *bool {
+	fs.String("config", "", "JSON file with settings and profiles, default "+configPath())
+	fs.String("profile", "", "profile from the config file or built in: "+strings.Join(profileNames(), ", "))
+
+	return fs.Bool("print-config", false, "print the merged settings and exit")
+}
+
+func configPath() string {
+	dir, e := os.UserConfigDir()
+
+	if e != nil {
+		return filepath.Join(z.CONFIG_DIR, z.CONFIG_FILE)
+	}
+
+	return filepath.Join(dir, z.CONFIG_DIR, z.CONFIG_FILE)
+}
+
+func profileNames() []string {
+	names := []string{}
+
+	for name := range profiles {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func loadProfile(config *z.Config, args []string) error {
+	path := lookupArg(args, "config")
+	profile := lookupArg(args, "profile")
+
+	if path == "" {
+		path = os.Getenv(z.ENV_PREFIX + "CONFIG")
+	}
+
+	if profile == "" {
+		profile = os.Getenv(z.ENV_PREFIX + "PROFILE")
+	}
+
+	explicit := path != ""
+
+	if !explicit {
+		path = configPath()
+	}
+
+	file := &ConfigFile{}
+	bs, e := ioutil.ReadFile(path)
+
+	switch {
+	case e == nil:
+		if e := json.Unmarshal(bs, config); e != nil {
+			return errors.New("could not read " + path + ": " + e.Error())
+		}
+
+		if e := json.Unmarshal(bs, file); e != nil {
+			return errors.New("could not read " + path + ": " + e.Error())
+		}
+
+	case explicit || !os.IsNotExist(e):
+		return e
+	}
+
+	if profile == "" {
+		profile = file.Profile
+	}
+
+	if profile != "" {
+		raw, ok := file.Profiles[profile]
+
+		if !ok {
+			s, ok := profiles[profile]
+
+			if !ok {
+				return errors.New("unknown profile " + profile + ", built in profiles are " + strings.Join(profileNames(), ", "))
+			}
+
+			raw = json.RawMessage(s)
+		}
+
+		if e := json.Unmarshal(raw, config); e != nil {
+			return errors.New("could not read profile " + profile + ": " + e.Error())
+		}
+	}
+
+	return loadEnv(config)
+}
+
+func lookupArg(args []string, name string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+
+		f := strings.TrimLeft(arg, "-")
+
+		if f == arg {
+			continue
+		}
+
+		if f == name && i+1 < len(args) {
+			return args[i+1]
+		}
+
+		if strings.HasPrefix(f, name+"=") {
+			return f[len(name)+1:]
+		}
+	}
+
+	return ""
+}
+
+func loadEnv(config *z.Config) error {
+	v := reflect.ValueOf(config).Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		key := z.ENV_PREFIX + envName(t.Field(i).Name)
+		s, ok := os.LookupEnv(key)
+
+		if !ok {
+			continue
+		}
+
+		if e := setField(v.Field(i), s); e != nil {
+			return errors.New(key + ": " + e.Error())
+		}
+	}
+
+	return nil
+}
+
+func envName(field string) string {
+	runes := []rune(field)
+	name := []rune{}
+
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && unicode.IsLower(runes[i-1]) {
+			name = append(name, '_')
+		}
+
+		name = append(name, unicode.ToUpper(r))
+	}
+
+	return string(name)
+}
+
+func setField(field reflect.Value, s string) error {
+	if color, ok := field.Addr().Interface().(*tb.Attribute); ok {
+		return (&colorFlag{color}).Set(s)
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(s)
+
+	case reflect.Bool:
+		b, e := strconv.ParseBool(s)
+
+		if e != nil {
+			return errors.New("must be true or false")
+		}
+
+		field.SetBool(b)
+
+	case reflect.Int, reflect.Int64:
+		n, e := strconv.ParseInt(s, 10, 64)
+
+		if e != nil {
+			return errors.New("must be a whole number")
+		}
+
+		field.SetInt(n)
+
+	default:
+		return errors.New("can only be set in the config file")
+	}
+
+	return nil
+}
+
+func printConfig(config *z.Config) string {
+	c := *config
+
+	if c.Password != "" {
+		c.Password = "hidden"
+	}
+
+	if c.Token != "" {
+		c.Token = "hidden"
+	}
+
+	if len(c.Tokens) > 0 {
+		c.Tokens = map[string]string{}
+
+		for name := range config.Tokens {
+			c.Tokens[name] = "hidden"
+		}
+	}
+
+	bs, _ := json.MarshalIndent(c, "", "  ")
+
+	return string(bs)
+}
@@ -0,0 +1,223 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	z "./common"
+)
+
+type ReplayHeader struct {
+	Version  int
+	Recorded time.Time
+	State    *z.Message
+}
+
+type ReplayFrame struct {
+	T int64
+	M *z.Message
+}
+
+type Recorder struct {
+	sync.Mutex
+
+	path    string
+	file    *os.File
+	zipper  *gzip.Writer
+	encoder *json.Encoder
+	started time.Time
+	flushed time.Time
+}
+
+func NewRecorder(path string, state *z.Message) (*Recorder, error) {
+	f, e := os.Create(path)
+
+	if e != nil {
+		return nil, e
+	}
+
+	zipper := gzip.NewWriter(f)
+	encoder := json.NewEncoder(zipper)
+	now := time.Now()
+
+	header := &ReplayHeader{Version: z.REPLAY_VERSION, Recorded: now, State: state}
+
+	if e := encoder.Encode(header); e != nil {
+		f.Close()
+
+		return nil, e
+	}
+
+	return &Recorder{
+		path:    path,
+		file:    f,
+		zipper:  zipper,
+		encoder: encoder,
+		started: now,
+		flushed: now,
+	}, nil
+}
+
+func (r *Recorder) Record(m *z.Message) {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.encoder == nil {
+		return
+	}
+
+	frame := &ReplayFrame{T: int64(time.Since(r.started) / time.Millisecond), M: m}
+
+	if e := r.encoder.Encode(frame); e != nil {
+		z.LogError(errors.New("Recorder: " + r.path + " " + e.Error()))
+
+		return
+	}
+
+	if time.Since(r.flushed) >= time.Second {
+		r.zipper.Flush()
+		r.flushed = time.Now()
+	}
+}
+
+func (r *Recorder) Close() error {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.encoder == nil {
+		return nil
+	}
+
+	r.encoder = nil
+
+	if e := r.zipper.Close(); e != nil {
+		r.file.Close()
+
+		return e
+	}
+
+	return r.file.Close()
+}
+
+type Replay struct {
+	Header *ReplayHeader
+	Frames []*ReplayFrame
+}
+
+func LoadReplay(path string) (*Replay, error) {
+	f, e := os.Open(path)
+
+	if e != nil {
+		return nil, e
+	}
+
+	defer f.Close()
+
+	zipper, e := gzip.NewReader(f)
+
+	if e != nil {
+		return nil, errors.New(path + " is not a replay: " + e.Error())
+	}
+
+	decoder := json.NewDecoder(zipper)
+	header := &ReplayHeader{}
+
+	if e := decoder.Decode(header); e != nil {
+		return nil, errors.New(path + " is not a replay: " + e.Error())
+	}
+
+	if header.Version != z.REPLAY_VERSION {
+		return nil, fmt.Errorf("%s is a version %d replay, this build plays version %d", path, header.Version, z.REPLAY_VERSION)
+	}
+
+	if header.State == nil {
+		return nil, errors.New(path + " has no initial state")
+	}
+
+	r := &Replay{Header: header}
+
+	for {
+		frame := &ReplayFrame{}
+
+		if e := decoder.Decode(frame); e != nil {
+			if e != io.EOF {
+				z.LogError(errors.New("Replay: " + path + " truncated: " + e.Error()))
+			}
+
+			break
+		}
+
+		if frame.M != nil {
+			r.Frames = append(r.Frames, frame)
+		}
+	}
+
+	return r, nil
+}
+
+func (r *Replay) Length() time.Duration {
+	if len(r.Frames) == 0 {
+		return 0
+	}
+
+	return time.Duration(r.Frames[len(r.Frames)-1].T) * time.Millisecond
+}
+
+func (g *Game) record() {
+	if g.config.Record == "" {
+		return
+	}
+
+	path := g.config.Record
+
+	if g.config.Server {
+		ext := filepath.Ext(path)
+		path = strings.TrimSuffix(path, ext) + "-" + g.config.Match + ext
+	}
+
+	r, e := NewRecorder(path, g.currentState("Replay"))
+
+	if e != nil {
+		g.announce(false, "Could not record: "+e.Error(), z.BoldColorRed)
+
+		return
+	}
+
+	g.recorder = r
+	g.gameManager.recorder = r
+
+	g.announce(false, "Recording to "+path, z.BoldColorWhite)
+}
+
+func (g *Game) StopRecording() {
+	if g.recorder == nil {
+		return
+	}
+
+	if e := g.recorder.Close(); e != nil {
+		z.LogError(errors.New("Recorder: " + e.Error()))
+	}
+}
@@ -0,0 +1,689 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	z "./common"
+)
+
+type Sender struct {
+	id        string
+	name      string
+	gameID    string
+	spectator bool
+
+	players map[string]bool
+	spawned map[string]bool
+	spawns  map[string][2]int
+	missles map[string][2]int
+	credits int
+	strikes []time.Time
+	dropped bool
+}
+
+type Referee struct {
+	senders map[string]*Sender
+
+	sync.Mutex
+}
+
+func NewReferee() *Referee {
+	return &Referee{senders: map[string]*Sender{}}
+}
+
+func (r *Referee) sender(id, name string, spectator bool) *Sender {
+	s, ok := r.senders[id]
+
+	if !ok {
+		s = &Sender{
+			id:        id,
+			name:      name,
+			spectator: spectator,
+			players:   map[string]bool{},
+			spawned:   map[string]bool{},
+			spawns:    map[string][2]int{},
+			missles:   map[string][2]int{},
+		}
+		r.senders[id] = s
+	}
+
+	return s
+}
+
+func (r *Referee) forget(name string) {
+	for id, s := range r.senders {
+		if strings.EqualFold(s.name, name) {
+			delete(r.senders, id)
+		}
+	}
+}
+
+func (r *Referee) claimed(gameID string) bool {
+	for _, s := range r.senders {
+		if s.gameID == gameID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Referee) strike(s *Sender) bool {
+	now := time.Now()
+	strikes := []time.Time{}
+
+	for _, t := range s.strikes {
+		if now.Sub(t) < z.CHEAT_PERIOD {
+			strikes = append(strikes, t)
+		}
+	}
+
+	s.strikes = append(strikes, now)
+
+	return len(s.strikes) >= z.CHEAT_STRIKES
+}
+
+func (g *Game) validate(m *z.Message) bool {
+	defer g.recover()
+
+	id := m.Params["Sender"]
+
+	if id == "" {
+		return false
+	}
+
+	g.referee.Lock()
+	defer g.referee.Unlock()
+
+	s := g.referee.sender(id, m.Params["SenderName"], m.Params["Spectator"] == "true")
+	gameID := m.Params["GameID"]
+
+	if s.dropped {
+		return false
+	}
+
+	if s.gameID == "" {
+		if gameID == g.id || g.referee.claimed(gameID) {
+			return g.offend(s, m, errors.New("game ID belongs to someone else"))
+		}
+
+		s.gameID = gameID
+	}
+
+	if gameID != s.gameID {
+		return g.offend(s, m, errors.New("game ID changed"))
+	}
+
+	if e := g.rule(s, m); e != nil {
+		return g.offend(s, m, e)
+	}
+
+	return true
+}
+
+func (g *Game) offend(s *Sender, m *z.Message, e error) bool {
+	log.Printf("Referee: %s %s %s %s: %s", s.name, m.Action, m.Class, m.ID, e.Error())
+	z.LogError(errors.New("Referee: " + s.name + " " + m.Action + ": " + e.Error()))
+
+	if g.referee.strike(s) {
+		s.dropped = true
+
+		reason := "disconnected for breaking the rules: " + e.Error()
+
+		if g.gameManager.networkManager.Drop(s.id, z.REJECT_CHEAT, reason) {
+			g.announce(true, s.name+" was disconnected for cheating", z.BoldColorRed)
+
+			go g.kick(true, s.name)
+		}
+	}
+
+	return false
+}
+
+func (g *Game) rule(s *Sender, m *z.Message) error {
+	if m.Action == "Chat" {
+		if !strings.EqualFold(m.Params["Name"], s.name) {
+			return errors.New("chat name does not match")
+		}
+
+		return nil
+	}
+
+	if m.Action == "Team" {
+		if !strings.EqualFold(m.Params["Name"], s.name) {
+			return errors.New("team name does not match")
+		}
+
+		if !z.ValidName(m.Params["Team"]) {
+			return errors.New("invalid team name")
+		}
+
+		return nil
+	}
+
+	if s.spectator {
+		return errors.New("spectators can only chat")
+	}
+
+	switch m.Action {
+	case "Announce":
+		return g.ruleAnnounce(s, m)
+
+	case "Sfx":
+		return g.ruleSfx(s, m)
+
+	case "Ready":
+		return s.owns(m.Params["Player"])
+
+	case "NewPlayer":
+		return g.ruleNewPlayer(s, m)
+
+	case "NewMissle":
+		return g.ruleNewMissle(s, m)
+
+	case "Run":
+		if e := s.owns(m.ID); e != nil {
+			return e
+		}
+
+		s.spawned[m.ID] = true
+
+		return nil
+
+	case "Start", "Stop":
+		return s.owns(m.ID)
+
+	case "Next":
+		return g.ruleNext(s, m)
+
+	case "SetMovement":
+		return g.ruleMovement(s, m)
+
+	case "SetPosition":
+		return g.ruleMove(s, m.Class, m.ID, m.Params["X"], m.Params["Y"])
+
+	case "Enter", "Leave":
+		return g.ruleMove(s, m.Params["Class"], m.Params["ID"], m.Params["X"], m.Params["Y"])
+
+	case "Stay", "Release":
+		return g.ruleNear(s, m.Class, m.ID)
+
+	case "ChangeHealth":
+		return g.ruleHealth(s, m)
+
+	case "ChangeStrength":
+		return g.ruleStrength(s, m)
+
+	case "ChangeTreasure":
+		return g.ruleTreasure(s, m)
+
+	case "Delete":
+		return g.ruleDelete(s, m)
+	}
+
+	return errors.New("action not allowed")
+}
+
+func (s *Sender) owns(id string) error {
+	if _, ok := s.missles[id]; ok || s.players[id] {
+		return nil
+	}
+
+	return errors.New("object is not owned by sender")
+}
+
+func (g *Game) ruleNewPlayer(s *Sender, m *z.Message) error {
+	if !strings.EqualFold(m.Params["Name"], s.name) {
+		return errors.New("player name does not match the connection")
+	}
+
+	for id := range s.players {
+		if igo, e := g.players.Get(id); e == nil && !igo.Deleted() {
+			return errors.New("sender already has a player")
+		}
+	}
+
+	if _, e := g.players.Get(m.Params["ID"]); e == nil {
+		return errors.New("player ID already in use")
+	}
+
+	s.players[m.Params["ID"]] = true
+
+	return nil
+}
+
+func (g *Game) ruleNewMissle(s *Sender, m *z.Message) error {
+	if s.credits <= 0 {
+		return errors.New("fired without strength")
+	}
+
+	x, ex := strconv.Atoi(m.Params["X"])
+	y, ey := strconv.Atoi(m.Params["Y"])
+
+	if ex != nil || ey != nil {
+		return errors.New("malformed position")
+	}
+
+	if pX, pY, ok := g.position(s); !ok || !g.near(pX, pY, x, y, z.MOVE_TOLERANCE) {
+		return errors.New("fired away from own player")
+	}
+
+	s.credits--
+	s.missles[m.Params["ID"]] = [2]int{x, y}
+
+	return nil
+}
+
+func (g *Game) ruleNext(s *Sender, m *z.Message) error {
+	if e := s.owns(m.ID); e != nil {
+		return e
+	}
+
+	x, ex := strconv.Atoi(m.Params["X"])
+	y, ey := strconv.Atoi(m.Params["Y"])
+
+	if ex != nil || ey != nil || x < -1 || x > 1 || y < -1 || y > 1 {
+		return errors.New("direction out of range")
+	}
+
+	if x != 0 && y != 0 {
+		if igo, e := g.players.Get(m.ID); e == nil {
+			if p, ok := igo.(z.IPlayer); ok {
+				if _, diagonal := p.GetMovement(); !diagonal {
+					return errors.New("diagonal move without diagonal movement")
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (g *Game) ruleMovement(s *Sender, m *z.Message) error {
+	if e := s.owns(m.ID); e != nil {
+		return e
+	}
+
+	if !z.ValidMovement(m.Params["Movement"]) {
+		return errors.New("unknown movement mode")
+	}
+
+	if _, e := strconv.ParseBool(m.Params["Diagonal"]); e != nil {
+		return errors.New("malformed diagonal setting")
+	}
+
+	return nil
+}
+
+func (g *Game) ruleMove(s *Sender, class, id, sx, sy string) error {
+	if e := s.owns(id); e != nil {
+		return e
+	}
+
+	x, ex := strconv.Atoi(sx)
+	y, ey := strconv.Atoi(sy)
+
+	if ex != nil || ey != nil || !g.rooms.Contains(x, y) {
+		return errors.New("position outside the world")
+	}
+
+	if class == "Missle" {
+		p := s.missles[id]
+
+		if !g.near(p[0], p[1], x, y, z.MOVE_TOLERANCE) {
+			return errors.New("missle jumped")
+		}
+
+		s.missles[id] = [2]int{x, y}
+
+		return nil
+	}
+
+	igo, e := g.players.Get(id)
+
+	if e != nil {
+		return nil
+	}
+
+	if !s.spawned[id] {
+		return g.ruleSpawn(s, id, x, y)
+	}
+
+	pX, pY := igo.GetPosition()
+
+	if !g.near(pX, pY, x, y, z.MOVE_TOLERANCE) {
+		return fmt.Errorf("moved from %d,%d to %d,%d", pX, pY, x, y)
+	}
+
+	return nil
+}
+
+func (g *Game) ruleSpawn(s *Sender, id string, x, y int) error {
+	if p, ok := s.spawns[id]; ok {
+		if p != [2]int{x, y} {
+			return fmt.Errorf("moved from spawn %d,%d to %d,%d before running", p[0], p[1], x, y)
+		}
+
+		return nil
+	}
+
+	if !g.rooms.HasRoom(x, y) {
+		return errors.New("spawned in a full room")
+	}
+
+	s.spawns[id] = [2]int{x, y}
+
+	return nil
+}
+
+func (g *Game) ruleNear(s *Sender, class, id string) error {
+	if s.owns(id) == nil {
+		return nil
+	}
+
+	x, y, ok := g.target(class, id)
+
+	if !ok {
+		return nil
+	}
+
+	if !g.reaches(s, x, y) {
+		return errors.New("target is out of reach")
+	}
+
+	return nil
+}
+
+func (g *Game) ruleSource(s *Sender, m *z.Message) error {
+	if m.Class == "Game" {
+		if m.ID != s.gameID {
+			return errors.New("event for another game")
+		}
+
+		return nil
+	}
+
+	return g.ruleNear(s, m.Class, m.ID)
+}
+
+func (g *Game) ruleAnnounce(s *Sender, m *z.Message) error {
+	if e := g.ruleSource(s, m); e != nil {
+		return e
+	}
+
+	status := []rune(m.Params["Status"])
+
+	if len(status) == 0 || len(status) > z.CHAT_LEN {
+		return errors.New("malformed announcement")
+	}
+
+	color, e := strconv.Atoi(m.Params["Color"])
+
+	if e != nil || !z.ValidColor(color) {
+		return errors.New("unknown announcement color")
+	}
+
+	return nil
+}
+
+func (g *Game) ruleSfx(s *Sender, m *z.Message) error {
+	if e := g.ruleSource(s, m); e != nil {
+		return e
+	}
+
+	if !z.ValidEffect(m.Params["Effect"]) {
+		return errors.New("unknown sound effect")
+	}
+
+	return nil
+}
+
+func (g *Game) ruleHealth(s *Sender, m *z.Message) error {
+	points, e := strconv.Atoi(m.Params["Points"])
+
+	if e != nil {
+		return errors.New("malformed points")
+	}
+
+	if _, ok := s.missles[m.ID]; ok {
+		return nil
+	}
+
+	if s.players[m.ID] {
+		if points <= 0 {
+			return nil
+		}
+
+		x, y, _ := g.position(s)
+
+		for _, h := range g.rooms.GetHealths(x, y) {
+			if h.GetPoints() == points {
+				return nil
+			}
+		}
+
+		return errors.New("health raised without a health item")
+	}
+
+	if points >= 0 {
+		return errors.New("healed someone else")
+	}
+
+	x, y, ok := g.target(m.Class, m.ID)
+
+	if !ok {
+		return nil
+	}
+
+	if len(s.missles) > 0 && -points <= z.MISSLE_STRENGTH {
+		for _, p := range s.missles {
+			if g.near(p[0], p[1], x, y, z.MOVE_TOLERANCE) {
+				return nil
+			}
+		}
+	}
+
+	pX, pY, ok := g.position(s)
+
+	if !ok || !g.near(pX, pY, x, y, z.MOVE_TOLERANCE) {
+		return errors.New("attacked out of reach")
+	}
+
+	if -points > g.strength(s) {
+		return errors.New("hit harder than own strength")
+	}
+
+	return nil
+}
+
+func (g *Game) ruleStrength(s *Sender, m *z.Message) error {
+	points, e := strconv.Atoi(m.Params["Points"])
+
+	if e != nil {
+		return errors.New("malformed points")
+	}
+
+	if !s.players[m.ID] {
+		return errors.New("changed someone else's strength")
+	}
+
+	if points == z.STRENGTH_LOST {
+		if g.strength(s) <= 0 {
+			return errors.New("used strength it does not have")
+		}
+
+		s.credits++
+
+		return nil
+	}
+
+	x, y, _ := g.position(s)
+
+	for _, st := range g.rooms.GetStrengths(x, y) {
+		if st.GetPoints() == points {
+			return nil
+		}
+	}
+
+	return errors.New("strength raised without a strength item")
+}
+
+func (g *Game) ruleTreasure(s *Sender, m *z.Message) error {
+	points, e := strconv.Atoi(m.Params["Points"])
+
+	if e != nil {
+		return errors.New("malformed points")
+	}
+
+	if !s.players[m.ID] {
+		return errors.New("changed someone else's treasure")
+	}
+
+	x, y, _ := g.position(s)
+
+	for _, t := range g.rooms.GetTreasures(x, y) {
+		if t.GetPoints() == points {
+			return nil
+		}
+	}
+
+	return errors.New("treasure raised without a treasure item")
+}
+
+func (g *Game) ruleDelete(s *Sender, m *z.Message) error {
+	if _, ok := s.missles[m.ID]; ok {
+		delete(s.missles, m.ID)
+
+		return nil
+	}
+
+	if s.players[m.ID] {
+		delete(s.spawned, m.ID)
+		delete(s.spawns, m.ID)
+
+		return nil
+	}
+
+	var gom *GameObjectMap
+
+	switch m.Class {
+	case "Health":
+		gom = g.healths
+
+	case "Strength":
+		gom = g.strengths
+
+	case "Treasure":
+		gom = g.treasures
+
+	default:
+		return errors.New("deleted someone else's object")
+	}
+
+	igo, e := gom.Get(m.ID)
+
+	if e != nil {
+		return nil
+	}
+
+	x, y := igo.GetPosition()
+
+	if !g.reaches(s, x, y) {
+		return errors.New("collected an item out of reach")
+	}
+
+	return nil
+}
+
+func (g *Game) position(s *Sender) (int, int, bool) {
+	for id := range s.players {
+		if igo, e := g.players.Get(id); e == nil && !igo.Deleted() {
+			x, y := igo.GetPosition()
+
+			return x, y, true
+		}
+	}
+
+	return 0, 0, false
+}
+
+func (g *Game) strength(s *Sender) int {
+	for id := range s.players {
+		if igo, e := g.players.Get(id); e == nil && !igo.Deleted() {
+			return igo.(z.ICreature).GetStrength()
+		}
+	}
+
+	return 0
+}
+
+func (g *Game) target(class, id string) (int, int, bool) {
+	gom := g.classGOM(class)
+
+	if gom == nil {
+		return 0, 0, false
+	}
+
+	igo, e := gom.Get(id)
+
+	if e != nil {
+		return 0, 0, false
+	}
+
+	x, y := igo.GetPosition()
+
+	return x, y, true
+}
+
+func (g *Game) reaches(s *Sender, x, y int) bool {
+	if pX, pY, ok := g.position(s); ok && g.near(pX, pY, x, y, z.MOVE_TOLERANCE) {
+		return true
+	}
+
+	for _, p := range s.missles {
+		if g.near(p[0], p[1], x, y, z.MOVE_TOLERANCE) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *Game) near(x1, y1, x2, y2, d int) bool {
+	distance := func(a, b, size int) int {
+		n := a - b
+
+		if n < 0 {
+			n = -n
+		}
+
+		if size-n < n {
+			n = size - n
+		}
+
+		return n
+	}
+
+	return distance(x1, x2, g.config.WorldWidth) <= d && distance(y1, y2, g.config.WorldHeight) <= d
+}
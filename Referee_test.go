@@ -0,0 +1,237 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"strconv"
+	"testing"
+
+	z "./common"
+	zgo "./gameobjects"
+)
+
+func newRefereeGame() (*Game, *Sender) {
+	g := NewGame(z.NewConfig())
+	g.config.WorldWidth, g.config.WorldHeight = 10, 10
+	g.broadcast = make(chan *z.Message, 100)
+	g.rooms = NewRooms(g.session, g.broadcast, 1, 10, 10)
+	g.players = NewGameObjectMap()
+
+	p := zgo.NewPlayer(false, g.broadcast, "other", 10, 10, g.rooms, "player", "p1", '@', z.BoldColorYellow)
+	p.SetPosition(false, 3, 3)
+	g.players.Set("p1", p)
+
+	s := g.referee.sender("c1", "player", false)
+	s.gameID = "other"
+	s.players["p1"] = true
+	s.spawned["p1"] = true
+
+	return g, s
+}
+
+func TestRuleMoveOccupiedRoom(t *testing.T) {
+	g, s := newRefereeGame()
+
+	o := zgo.NewPlayer(false, g.broadcast, g.id, 10, 10, g.rooms, "other", "p2", '@', z.BoldColorMagenta)
+	o.SetPosition(false, 4, 3)
+	g.rooms.Enter(false, 4, 3, o)
+
+	if g.rooms.HasRoom(4, 3) {
+		t.Fatal("room should be full")
+	}
+
+	if e := g.ruleMove(s, "Player", "p1", "4", "3"); e != nil {
+		t.Fatalf("move into occupied room rejected: %v", e)
+	}
+}
+
+func TestRuleMoveOutOfRange(t *testing.T) {
+	g, s := newRefereeGame()
+
+	for _, p := range [][2]int{{-1, 3}, {3, -1}, {10, 3}, {3, 10}, {100, 100}} {
+		if e := g.ruleMove(s, "Player", "p1", strconv.Itoa(p[0]), strconv.Itoa(p[1])); e == nil {
+			t.Errorf("move to %d,%d accepted", p[0], p[1])
+		}
+	}
+
+	if e := g.ruleMove(s, "Player", "p1", "x", "3"); e == nil {
+		t.Error("malformed position accepted")
+	}
+}
+
+func TestRuleMoveBeforeSpawn(t *testing.T) {
+	g, s := newRefereeGame()
+	delete(s.spawned, "p1")
+
+	if e := g.ruleMove(s, "Player", "p1", "8", "8"); e != nil {
+		t.Fatalf("spawn placement rejected: %v", e)
+	}
+
+	if e := g.ruleMove(s, "Player", "p1", "8", "8"); e != nil {
+		t.Errorf("entering the spawn room rejected: %v", e)
+	}
+
+	if e := g.ruleMove(s, "Player", "p1", "1", "1"); e == nil {
+		t.Error("teleport before running accepted")
+	}
+
+	if e := g.rule(s, z.NewMessage("Player", "p1", "Delete")); e != nil {
+		t.Fatalf("deleting own player rejected: %v", e)
+	}
+
+	if s.spawned["p1"] || len(s.spawns) > 0 {
+		t.Error("spawn state kept after delete")
+	}
+}
+
+func TestRefereeForgetsSender(t *testing.T) {
+	g, _ := newRefereeGame()
+
+	g.remove("Player")
+
+	if len(g.referee.senders) > 0 {
+		t.Error("sender kept after the player left")
+	}
+}
+
+func TestRuleHostActions(t *testing.T) {
+	g, s := newRefereeGame()
+
+	if e := g.rule(s, z.NewMessage("Game", "other", "Pause")); e == nil {
+		t.Error("pause from a client accepted")
+	}
+
+	announce := func(id, status string, color int) error {
+		m := z.NewMessage("Game", id, "Announce")
+		m.Params["Status"] = status
+		m.Params["Color"] = strconv.Itoa(color)
+
+		return g.rule(s, m)
+	}
+
+	if e := announce("other", "Pausing game", int(z.BoldColorWhite)); e != nil {
+		t.Errorf("announcement rejected: %v", e)
+	}
+
+	if e := announce(g.id, "Pausing game", int(z.BoldColorWhite)); e == nil {
+		t.Error("announcement for another game accepted")
+	}
+
+	if e := announce("other", "", int(z.BoldColorWhite)); e == nil {
+		t.Error("empty announcement accepted")
+	}
+
+	if e := announce("other", "Pausing game", 1000); e == nil {
+		t.Error("unknown color accepted")
+	}
+
+	sfx := z.NewMessage("Player", "p1", "Sfx")
+	sfx.Params["Effect"] = "die"
+
+	if e := g.rule(s, sfx); e != nil {
+		t.Errorf("sound effect rejected: %v", e)
+	}
+
+	sfx.Params["Effect"] = "../../etc/passwd"
+
+	if e := g.rule(s, sfx); e == nil {
+		t.Error("unknown sound effect accepted")
+	}
+}
+
+func TestRuleMoveOwnership(t *testing.T) {
+	g, s := newRefereeGame()
+
+	if e := g.ruleMove(s, "Player", "p2", "3", "3"); e == nil {
+		t.Error("move of someone else's player accepted")
+	}
+}
+
+func TestRuleMoveJump(t *testing.T) {
+	g, s := newRefereeGame()
+
+	if e := g.ruleMove(s, "Player", "p1", "4", "3"); e != nil {
+		t.Errorf("step rejected: %v", e)
+	}
+
+	if e := g.ruleMove(s, "Player", "p1", "8", "8"); e == nil {
+		t.Error("jump accepted")
+	}
+}
+
+func TestRuleNewMissleNeedsStrength(t *testing.T) {
+	g, s := newRefereeGame()
+
+	m := z.NewMessage("Missle", "m1", "NewMissle")
+	m.Params["ID"] = "m1"
+	m.Params["X"] = "3"
+	m.Params["Y"] = "3"
+
+	if e := g.rule(s, m); e == nil {
+		t.Fatal("missle without strength accepted")
+	}
+
+	s.credits = 1
+
+	if e := g.rule(s, m); e != nil {
+		t.Fatalf("missle rejected: %v", e)
+	}
+
+	if _, ok := s.missles["m1"]; !ok || s.credits != 0 {
+		t.Error("missle not recorded against the sender")
+	}
+}
+
+func TestRuleHealthWithoutItem(t *testing.T) {
+	g, s := newRefereeGame()
+
+	m := z.NewMessage("Player", "p1", "ChangeHealth")
+	m.Params["Points"] = "10"
+
+	if e := g.rule(s, m); e == nil {
+		t.Error("health raised without a health item accepted")
+	}
+}
+
+func TestRuleSpectatorOnlyChats(t *testing.T) {
+	g, _ := newRefereeGame()
+	s := g.referee.sender("c2", "watcher", true)
+
+	chat := z.NewMessage("Chat", "c2", "Chat")
+	chat.Params["Name"] = "Watcher"
+
+	if e := g.rule(s, chat); e != nil {
+		t.Errorf("spectator chat rejected: %v", e)
+	}
+
+	if e := g.rule(s, z.NewMessage("Player", "p1", "Start")); e == nil {
+		t.Error("spectator action accepted")
+	}
+}
+
+func TestRefereeStrikes(t *testing.T) {
+	g, s := newRefereeGame()
+
+	for i := 1; i < z.CHEAT_STRIKES; i++ {
+		if g.referee.strike(s) {
+			t.Fatalf("dropped after %d strikes", i)
+		}
+	}
+
+	if !g.referee.strike(s) {
+		t.Error("not dropped after the last strike")
+	}
+}
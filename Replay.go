@@ -0,0 +1,376 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	tb "github.com/nsf/termbox-go"
+
+	z "./common"
+	zgo "./gameobjects"
+)
+
+type Playback struct {
+	sync.Mutex
+
+	replay *Replay
+	next   int
+	clock  time.Duration
+	speed  float64
+	paused bool
+}
+
+func NewPlayback(replay *Replay) *Playback {
+	return &Playback{
+		replay: replay,
+		speed:  1,
+	}
+}
+
+func watch(args []string) {
+	fs := newFlagSet("replay")
+	config := z.NewConfig()
+	screenFlags(fs, config)
+	keymapFlags(fs, config)
+
+	parseFlags(fs, args)
+
+	if fs.NArg() != 1 {
+		usageError("replay needs one replay file")
+	}
+
+	if e := validateFPS(config); e != nil {
+		usageError(e.Error())
+	}
+
+	var e error
+	theme, e = LoadTheme(config.Theme, config.Symbols)
+
+	if e != nil {
+		usageError(e.Error())
+	}
+
+	keymap, e = LoadKeymap(config.Keymap)
+
+	if e != nil {
+		usageError(e.Error())
+	}
+
+	keys, e := keymap.Replay()
+
+	if e != nil {
+		usageError(e.Error())
+	}
+
+	replay, e := LoadReplay(fs.Arg(0))
+
+	if e != nil {
+		println("Error: " + e.Error())
+
+		os.Exit(1)
+	}
+
+	initTerminal()
+
+	game = NewGame(z.NewConfig())
+	game.SetTheme(theme)
+
+	if e := game.StartReplay(replay); e != nil {
+		tb.Close()
+
+		println("Error: " + e.Error())
+
+		os.Exit(1)
+	}
+
+	game.Play()
+	game.Help(keys.ReplayHelp())
+
+	go replayInput(keys)
+	play(config.FPS)
+
+	tb.Clear(tb.ColorDefault, tb.ColorDefault)
+	tb.Close()
+}
+
+func replayInput(keys *Keymap) {
+	defer mainRecover()
+
+	for !getQuit() {
+		switch ev := tb.PollEvent(); ev.Type {
+		case tb.EventKey:
+			switch keys.Action(ev) {
+			case "Pause":
+				game.PauseReplay()
+
+			case "SeekBack":
+				game.SeekReplay(-z.REPLAY_SEEK)
+
+			case "SeekForward":
+				game.SeekReplay(z.REPLAY_SEEK)
+
+			case "Faster":
+				game.SpeedReplay(2)
+
+			case "Slower":
+				game.SpeedReplay(0.5)
+
+			case "Restart":
+				game.SeekReplay(-game.playback.replay.Length())
+
+			case "ScrollUp":
+				game.ScrollChat(1)
+
+			case "ScrollDown":
+				game.ScrollChat(-1)
+
+			case "Scoreboard":
+				game.ToggleScoreboard()
+
+			case "Quit":
+				setQuit(true)
+
+			case "CameraPrev":
+				game.CycleCamera(-1)
+
+			case "CameraNext":
+				game.CycleCamera(1)
+
+			case "Minimap":
+				game.ToggleMinimap()
+			}
+		case tb.EventResize:
+			game.Resize(ev.Width, ev.Height)
+		case tb.EventInterrupt:
+			setQuit(true)
+		}
+	}
+}
+
+func (g *Game) StartReplay(replay *Replay) error {
+	defer g.recover()
+
+	state := replay.Header.State
+	config := &z.Config{}
+
+	if e := json.Unmarshal([]byte(state.Params["Config"]), config); e != nil {
+		return errors.New("replay has no valid config: " + e.Error())
+	}
+
+	config.Multiplayer = false
+	config.Server = false
+	config.Headless = false
+	config.Spectator = true
+	config.Dynamic = false
+	config.Discovery = false
+
+	g.config = config
+	g.session = state.Params["Session"]
+	g.playback = NewPlayback(replay)
+
+	if e := g.init(); e != nil {
+		return e
+	}
+
+	g.display = true
+
+	g.createWorld()
+	g.rewind()
+
+	g.announce(false, "Replay recorded "+replay.Header.Recorded.Format("2006-01-02 15:04"), z.BoldColorWhite)
+	g.announce(false, "Length "+timecode(replay.Length()), z.BoldColorWhite)
+	g.announce(false, "The keys are shown below", z.BoldColorWhite)
+
+	g.CycleCamera(1)
+
+	go g.runReplay()
+
+	return nil
+}
+
+func (g *Game) rewind() {
+	state := g.playback.replay.Header.State
+
+	g.clearObjects()
+	g.lobby.Clear()
+
+	g.player = nil
+
+	g.resetWorld()
+	g.lobby.Load(state.MultiParams["Lobby"])
+	g.loadState(state)
+
+	g.phase = z.PHASE_SPECTATING
+	g.paused = false
+}
+
+func (g *Game) runReplay() {
+	defer g.recover()
+
+	p := g.playback
+
+	for !g.Finished() {
+		time.Sleep(z.REPLAY_TICK)
+
+		p.Lock()
+
+		if !p.paused {
+			p.clock += time.Duration(float64(z.REPLAY_TICK) * p.speed)
+
+			g.replayTo(p.clock, false)
+
+			if p.next >= len(p.replay.Frames) {
+				p.paused = true
+
+				g.announce(false, "Replay finished", z.BoldColorWhite)
+			}
+		}
+
+		p.Unlock()
+	}
+}
+
+func (g *Game) replayTo(clock time.Duration, seeking bool) {
+	p := g.playback
+	frames := p.replay.Frames
+
+	for p.next < len(frames) && time.Duration(frames[p.next].T)*time.Millisecond <= clock {
+		g.replayFrame(frames[p.next].M, seeking)
+
+		p.next++
+	}
+}
+
+func (g *Game) replayFrame(m *z.Message, seeking bool) {
+	defer g.recover()
+
+	switch m.Action {
+	case "Sfx", "Announce":
+		if seeking {
+			return
+		}
+
+	case "NewMissle":
+		x, _ := strconv.Atoi(m.Params["X"])
+		y, _ := strconv.Atoi(m.Params["Y"])
+		nextX, _ := strconv.Atoi(m.Params["NextX"])
+		nextY, _ := strconv.Atoi(m.Params["NextY"])
+		id := m.Params["ID"]
+
+		missle := zgo.NewMissle(false, g.broadcast, g.id, g.config.WorldWidth, g.config.WorldHeight, g.rooms, m.Params["Creature"], id, x, y, nextX, nextY)
+		g.missles.Set(id, missle)
+
+		if !seeking {
+			g.sfx(false, "fire")
+		}
+
+		return
+	}
+
+	g.gameManager.dispatch(m)
+}
+
+func (g *Game) PauseReplay() {
+	defer g.recover()
+
+	p := g.playback
+	p.Lock()
+	defer p.Unlock()
+
+	if p.next >= len(p.replay.Frames) {
+		g.seek(0)
+	}
+
+	p.paused = !p.paused
+
+	g.replayStatus()
+}
+
+func (g *Game) SeekReplay(delta time.Duration) {
+	defer g.recover()
+
+	p := g.playback
+	p.Lock()
+	defer p.Unlock()
+
+	g.seek(p.clock + delta)
+
+	g.replayStatus()
+}
+
+func (g *Game) seek(clock time.Duration) {
+	p := g.playback
+
+	if clock < 0 {
+		clock = 0
+	}
+
+	if length := p.replay.Length(); clock > length {
+		clock = length
+	}
+
+	if clock < p.clock {
+		g.rewind()
+
+		p.next = 0
+	}
+
+	p.clock = clock
+
+	g.replayTo(clock, true)
+}
+
+func (g *Game) SpeedReplay(factor float64) {
+	defer g.recover()
+
+	p := g.playback
+	p.Lock()
+	defer p.Unlock()
+
+	speed := p.speed * factor
+
+	if speed < z.REPLAY_MIN_SPEED || speed > z.REPLAY_MAX_SPEED {
+		return
+	}
+
+	p.speed = speed
+
+	g.replayStatus()
+}
+
+func (g *Game) replayStatus() {
+	p := g.playback
+	s := fmt.Sprintf("%s/%s x%g", timecode(p.clock), timecode(p.replay.Length()), p.speed)
+
+	if p.paused {
+		s += " paused"
+	}
+
+	g.announce(false, s, z.BoldColorCyan)
+}
+
+func timecode(d time.Duration) string {
+	s := int(d / time.Second)
+
+	return fmt.Sprintf("%02d:%02d", s/60, s%60)
+}
@@ -0,0 +1,168 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	z "./common"
+)
+
+func (g *Game) watchRounds() {
+	go func() {
+		defer g.recover()
+
+		for !g.Finished() {
+			if g.phase == z.PHASE_PLAYING && g.roundOver() {
+				g.endRound()
+			}
+
+			time.Sleep(1000 * time.Millisecond)
+		}
+	}()
+}
+
+func (g *Game) roundOver() bool {
+	n := g.players.Len()
+
+	if n > 0 {
+		g.contested = true
+	}
+
+	if g.treasures.Len() == 0 {
+		return true
+	}
+
+	return g.contested && n == 0
+}
+
+func (g *Game) endRound() {
+	broadcast := g.config.Multiplayer
+
+	s := fmt.Sprintf("Round %d over", g.round)
+	g.announce(broadcast, s, z.BoldColorWhite)
+
+	scores := g.scores()
+
+	if len(scores) > 0 {
+		s = fmt.Sprintf("%s wins with %d treasure", scores[0].Name, scores[0].Treasure)
+		g.announce(broadcast, s, scores[0].Color)
+	} else {
+		g.announce(broadcast, "No survivors", z.BoldColorRed)
+	}
+
+	g.pause(broadcast, true)
+
+	if g.config.Rounds > 0 && g.round >= g.config.Rounds {
+		g.announce(broadcast, "Match finished", z.BoldColorWhite)
+
+		if g.announcer != nil {
+			g.announcer.Close()
+		}
+
+		time.Sleep(1 * time.Second)
+
+		g.StopRecording()
+		g.gameManager.Close()
+		g.finish()
+
+		return
+	}
+
+	s = fmt.Sprintf("Next round in %d seconds", z.ROUND_DELAY/time.Second)
+	g.announce(broadcast, s, z.BoldColorWhite)
+
+	time.Sleep(z.ROUND_DELAY)
+
+	g.nextRound()
+}
+
+func (g *Game) nextRound() {
+	g.round++
+	g.contested = false
+
+	s := fmt.Sprintf("Starting round %d", g.round)
+	g.announce(false, s, z.BoldColorWhite)
+
+	g.clearObjects()
+	g.lobby.Clear()
+
+	g.resetWorld()
+	g.populateWorld()
+	g.runCreatures(false, nil)
+
+	lobby := g.config.Multiplayer && g.config.Lobby
+
+	if lobby {
+		g.phase = z.PHASE_LOBBY
+	}
+
+	msg := g.currentState("Round")
+	g.hideState(msg)
+	g.sight.Reset()
+	g.broadcast <- msg
+
+	if lobby {
+		g.openLobby()
+	} else {
+		g.pause(false, false)
+	}
+}
+
+func (g *Game) loadRound(broadcast bool, m *z.Message) {
+	defer g.recover()
+
+	g.clearObjects()
+	g.lobby.Clear()
+
+	g.player = nil
+	g.camera = ""
+	g.paused = true
+	g.phase = m.Params["Phase"]
+	g.lobby.Load(m.MultiParams["Lobby"])
+
+	g.announce(false, "Loading next round", z.BoldColorWhite)
+
+	g.resetWorld()
+	g.loadState(m)
+	g.join()
+}
+
+func (g *Game) clearObjects() {
+	goms := []*GameObjectMap{g.players, g.monsters, g.bombs, g.portals, g.missles, g.healths, g.strengths, g.treasures}
+
+	for _, gom := range goms {
+		for _, igo := range gom.GetValues() {
+			igo.Stop(false)
+			igo.Delete(false)
+		}
+	}
+}
+
+func (g *Game) Finished() bool {
+	g.RLock()
+	defer g.RUnlock()
+
+	return g.finished
+}
+
+func (g *Game) finish() {
+	g.Lock()
+	defer g.Unlock()
+
+	g.finished = true
+}
@@ -0,0 +1,251 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	z "./common"
+	zgo "./gameobjects"
+)
+
+func (g *Game) OpenSave() {
+	if g.config.Multiplayer || g.playback != nil {
+		g.announce(false, "Saving is single player only", z.BoldColorRed)
+
+		return
+	}
+
+	g.chats.Ask("Save slot")
+}
+
+func (g *Game) SaveGame(slot string) {
+	defer g.recover()
+
+	if slot == "" {
+		slot = z.QUICKSAVE_SLOT
+	}
+
+	if e := g.save(slot); e != nil {
+		g.announce(false, "Could not save: "+e.Error(), z.BoldColorRed)
+
+		return
+	}
+
+	g.announce(false, "Game saved to "+slot, z.BoldColorWhite)
+}
+
+func (g *Game) autosave() {
+	if g.config.Multiplayer {
+		return
+	}
+
+	if e := g.save(z.AUTOSAVE_SLOT); e != nil {
+		g.announce(false, "Could not autosave: "+e.Error(), z.BoldColorRed)
+
+		return
+	}
+
+	g.announce(false, "Game autosaved", z.BoldColorWhite)
+}
+
+func (g *Game) watchAutosave() {
+	go func() {
+		defer g.recover()
+
+		for !g.Finished() {
+			time.Sleep(z.AUTOSAVE_PERIOD)
+
+			if !g.Finished() && !g.paused {
+				g.autosave()
+			}
+		}
+	}()
+}
+
+func (g *Game) save(slot string) error {
+	if g.config.Multiplayer || g.playback != nil {
+		return errors.New("only single player games can be saved")
+	}
+
+	state := g.currentState("Save")
+	state.MultiParams["Players"] = g.savedPlayers()
+
+	player := ""
+
+	if g.player != nil && !g.player.Deleted() {
+		player = g.player.GetID()
+	}
+
+	s := &z.SaveGame{
+		Version: z.SAVE_VERSION,
+		Saved:   time.Now(),
+		Slot:    slot,
+		Round:   g.round,
+		Player:  player,
+		State:   state,
+	}
+
+	return z.WriteSave(s)
+}
+
+func (g *Game) savedPlayers() []string {
+	players := []string{}
+
+	for _, igo := range g.players.GetValues() {
+		bs, _ := json.Marshal(igo)
+		players = append(players, string(bs))
+	}
+
+	return players
+}
+
+func (g *Game) loadGame(slot string) error {
+	s, e := z.ReadSave(slot)
+
+	if e != nil {
+		return e
+	}
+
+	config := &z.Config{}
+
+	if e := json.Unmarshal([]byte(s.State.Params["Config"]), config); e != nil {
+		return errors.New("save has no valid config: " + e.Error())
+	}
+
+	config.Multiplayer = false
+	config.Server = false
+	config.Headless = false
+	config.Spectator = false
+	config.Dynamic = false
+	config.Discovery = false
+	config.Volume = g.config.Volume
+	config.Record = g.config.Record
+	config.Movement = g.config.Movement
+	config.Diagonal = g.config.Diagonal
+	config.Minimap = g.config.Minimap
+	config.Explored = g.config.Explored
+	config.FPS = g.config.FPS
+	config.Keymap = g.config.Keymap
+	config.Theme = g.config.Theme
+	config.Symbols = g.config.Symbols
+	config.Init()
+
+	g.config = config
+	g.session = s.State.Params["Session"]
+	g.display = true
+
+	g.createWorld()
+
+	g.announce(false, "Loading "+slot, z.BoldColorWhite)
+
+	if e := g.restore(s.State); e != nil {
+		return e
+	}
+
+	if igo, e := g.players.Get(s.Player); e == nil {
+		g.player = igo.(*zgo.Player)
+		g.player.SetMovement(false, g.config.Movement, g.config.Diagonal)
+	}
+
+	g.stopCreatures(false)
+	g.runCreatures(false, nil)
+
+	g.watchAutosave()
+
+	if s.Round > 1 {
+		g.round = s.Round
+	}
+
+	g.record()
+
+	g.announce(false, "Saved "+s.Saved.Format("2006-01-02 15:04"), z.BoldColorWhite)
+	g.announce(false, "Press enter to resume", z.BoldColorWhite)
+
+	return nil
+}
+
+func (g *Game) restore(state *z.Message) error {
+	goms := map[string]*GameObjectMap{
+		"Player":   g.players,
+		"Monster":  g.monsters,
+		"Bomb":     g.bombs,
+		"Portal":   g.portals,
+		"Missle":   g.missles,
+		"Health":   g.healths,
+		"Strength": g.strengths,
+		"Treasure": g.treasures,
+	}
+
+	classes := []string{"Player", "Monster", "Bomb", "Portal", "Missle", "Health", "Strength", "Treasure"}
+
+	for _, class := range classes {
+		for _, o := range state.MultiParams[class+"s"] {
+			igo := g.blankGO(class)
+
+			if e := json.Unmarshal([]byte(o), igo); e != nil {
+				return errors.New("could not load " + class + ": " + e.Error())
+			}
+
+			if igo.Deleted() {
+				continue
+			}
+
+			goms[class].Set(igo.GetID(), igo)
+			x, y := igo.GetPosition()
+			g.rooms.Enter(false, x, y, igo)
+		}
+	}
+
+	for _, igo := range g.monsters.GetValues() {
+		m := igo.(z.IMonster)
+		m.LoadPlayer()
+	}
+
+	return nil
+}
+
+func (g *Game) blankGO(class string) z.IGameObject {
+	w, h := g.config.WorldWidth, g.config.WorldHeight
+
+	switch class {
+	case "Player":
+		return zgo.NewPlayer(false, g.broadcast, g.id, w, h, g.rooms, g.config.Name, "", '☻', g.config.Color)
+
+	case "Monster":
+		return zgo.NewMonster(g.broadcast, w, h, g.rooms, g.players, g.config.Difficulty)
+
+	case "Bomb":
+		return zgo.NewBomb(g.broadcast, g.rooms)
+
+	case "Portal":
+		return zgo.NewPortal(g.broadcast, w, h, g.rooms)
+
+	case "Missle":
+		return zgo.NewMissle(false, g.broadcast, g.id, w, h, g.rooms, "", "", 0, 0, 0, 0)
+
+	case "Health":
+		return zgo.NewHealth(g.broadcast)
+
+	case "Strength":
+		return zgo.NewStrength(g.broadcast)
+
+	default:
+		return zgo.NewTreasure(g.broadcast)
+	}
+}
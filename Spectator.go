@@ -0,0 +1,109 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"sort"
+
+	z "./common"
+)
+
+func (g *Game) ToggleScoreboard() {
+	defer g.recover()
+
+	g.scoreboard = !g.scoreboard
+}
+
+func (g *Game) CycleCamera(step int) {
+	defer g.recover()
+
+	if g.phase != z.PHASE_SPECTATING {
+		return
+	}
+
+	scores := g.scores()
+
+	sort.Slice(scores, func(i, j int) bool { return scores[i].Name < scores[j].Name })
+
+	if len(scores) == 0 {
+		g.camera = ""
+
+		return
+	}
+
+	index := 0
+
+	for i, s := range scores {
+		if s.ID == g.camera {
+			index = (i + step + len(scores)) % len(scores)
+
+			break
+		}
+	}
+
+	g.camera = scores[index].ID
+
+	g.announce(false, "Following "+scores[index].Name, scores[index].Color)
+}
+
+func (g *Game) followed() z.ICreature {
+	if g.player != nil {
+		return g.player
+	}
+
+	igo, e := g.players.Get(g.camera)
+
+	if e != nil {
+		return nil
+	}
+
+	c, _ := igo.(z.ICreature)
+
+	return c
+}
+
+func (g *Game) scores() []*z.Score {
+	scores := []*z.Score{}
+
+	for _, igo := range g.players.GetValues() {
+		c, ok := igo.(z.ICreature)
+
+		if !ok || c.Deleted() {
+			continue
+		}
+
+		s := &z.Score{
+			ID:       c.GetID(),
+			Name:     c.GetName(),
+			Color:    c.GetColor(),
+			Health:   c.GetHealth(),
+			Strength: c.GetStrength(),
+			Treasure: c.GetTreasure(),
+		}
+
+		scores = append(scores, s)
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Treasure != scores[j].Treasure {
+			return scores[i].Treasure > scores[j].Treasure
+		}
+
+		return scores[i].Name < scores[j].Name
+	})
+
+	return scores
+}
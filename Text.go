@@ -1,33 +1,32 @@
-/*
-Zahhak2, a Golang console game.
-Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
-This program is free software: you can redistribute it and/or modify
-it under the terms of the GNU General Public License as published by
-the Free Software Foundation, either version 3 of the License, or
-(at your option) any later version.
-This program is distributed in the hope that it will be useful,
-but WITHOUT ANY WARRANTY; without even the implied warranty of
-MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
-GNU General Public License for more details.
-You should have received a copy of the GNU General Public License
-along with this program.  If not, see <http://www.gnu.org/licenses/>.
-*/
-
-package main
-
-const GOODBYE = `
-
-By Aryo Pehlewan aryopehlewan@hotmail.com Copyright 2021 License GPLv3
-
-8888888888P          888      888               888       .d8888b.  
-      d88P           888      888               888      d88P  Y88b 
-     d88P            888      888               888             888 
-    d88P     8888b.  88888b.  88888b.   8888b.  888  888      .d88P 
-   d88P         "88b 888 "88b 888 "88b     "88b 888 .88P  .od888P"  
-  d88P      .d888888 888  888 888  888 .d888888 888888K  d88P"      
- d88P       888  888 888  888 888  888 888  888 888 "88b 888"       
-d8888888888 "Y888888 888  888 888  888 "Y888888 888  888 888888888  
-`
-
-//Type 'zahak2 help' for options
-//`
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+const GOODBYE = `
+
+By Aryo Pehlewan aryopehlewan@hotmail.com Copyright 2021 License GPLv3
+
+8888888888P          888      888               888       .d8888b.  
+      d88P           888      888               888      d88P  Y88b 
+     d88P            888      888               888             888 
+    d88P     8888b.  88888b.  88888b.   8888b.  888  888      .d88P 
+   d88P         "88b 888 "88b 888 "88b     "88b 888 .88P  .od888P"  
+  d88P      .d888888 888  888 888  888 .d888888 888888K  d88P"      
+ d88P       888  888 888  888 888  888 888  888 888 "88b 888"       
+d8888888888 "Y888888 888  888 888  888 "Y888888 888  888 888888888  
+
+Type 'zahhak2 help' for options
+`
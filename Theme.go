@@ -0,0 +1,349 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	tb "github.com/nsf/termbox-go"
+
+	z "./common"
+	zc "./canvas"
+)
+
+var colorKeys = []string{"Black", "Red", "Green", "Yellow", "Blue", "Magenta", "Cyan", "White"}
+
+var rgbColors = map[string][2][3]uint8{
+	"Black":   {{0, 0, 0}, {127, 127, 127}},
+	"Red":     {{205, 0, 0}, {255, 85, 85}},
+	"Green":   {{0, 205, 0}, {85, 255, 85}},
+	"Yellow":  {{205, 205, 0}, {255, 255, 85}},
+	"Blue":    {{0, 0, 238}, {92, 92, 255}},
+	"Magenta": {{205, 0, 205}, {255, 85, 255}},
+	"Cyan":    {{0, 205, 205}, {85, 255, 255}},
+	"White":   {{229, 229, 229}, {255, 255, 255}},
+}
+
+var palettes = map[string]map[string][]string{
+	"default": {},
+	"high-contrast": {
+		"BoldBlack": {"#8a8a8a", "245", "white"},
+		"Red":       {"#ff4040", "203", "red"},
+		"Green":     {"#40ff40", "83", "green"},
+		"Yellow":    {"#ffff40", "227", "yellow"},
+		"Blue":      {"#70b0ff", "75", "blue"},
+		"Magenta":   {"#ff70ff", "213", "magenta"},
+		"Cyan":      {"#40ffff", "87", "cyan"},
+		"White":     {"#ffffff", "231", "white"},
+	},
+	"colour-blind": {
+		"Red":     {"#d55e00", "166", "red"},
+		"Green":   {"#009e73", "36", "green"},
+		"Yellow":  {"#f0e442", "227", "yellow"},
+		"Blue":    {"#56b4e9", "74", "blue"},
+		"Magenta": {"#cc79a7", "175", "magenta"},
+		"Cyan":    {"#e69f00", "214", "cyan"},
+	},
+	"monochrome": {
+		"Red":     {"white"},
+		"Green":   {"white"},
+		"Yellow":  {"white"},
+		"Blue":    {"white"},
+		"Magenta": {"white"},
+		"Cyan":    {"white"},
+	},
+}
+
+var symbolSets = map[string]map[string]string{
+	z.SYMBOLS_UNICODE: {},
+	z.SYMBOLS_ASCII: {
+		"☻": "@", "☺": "&", "☼": "M", "▲": "^", "◘": "O", "·": ".",
+		"░": ":", "▒": "+", "▓": "#",
+		"═": "-", "║": "|", "╔": "+", "╗": "+", "╚": "+", "╝": "+", "╦": "+", "╩": "+", "╠": "+",
+	},
+}
+
+type ThemeFile struct {
+	Palette string
+	Symbols string
+	Colors  map[string][]string
+	Glyphs  map[string]string
+}
+
+func LoadTheme(name, symbols string) (*zc.Theme, error) {
+	file := &ThemeFile{Palette: name}
+
+	if _, ok := palettes[name]; !ok {
+		path := name
+
+		if path == "" {
+			path = filepath.Join(filepath.Dir(configPath()), z.THEME_FILE)
+		}
+
+		bs, e := ioutil.ReadFile(path)
+
+		switch {
+		case e == nil:
+			file.Palette = ""
+
+			if e := json.Unmarshal(bs, file); e != nil {
+				return nil, errors.New("could not read " + path + ": " + e.Error())
+			}
+
+		case name != "" && os.IsNotExist(e):
+			return nil, errors.New("theme " + name + " is neither a palette (" + strings.Join(paletteNames(), ", ") + ") nor a file")
+
+		case !os.IsNotExist(e):
+			return nil, e
+		}
+	}
+
+	if symbols != "" {
+		file.Symbols = symbols
+	}
+
+	return NewTheme(file, colorDepth())
+}
+
+func NewTheme(file *ThemeFile, depth tb.OutputMode) (*zc.Theme, error) {
+	colors := map[string][]string{}
+
+	if file.Palette != "" {
+		palette, ok := palettes[file.Palette]
+
+		if !ok {
+			return nil, errors.New("unknown palette " + file.Palette + ", palettes are " + strings.Join(paletteNames(), ", "))
+		}
+
+		for key, values := range palette {
+			colors[key] = values
+		}
+	}
+
+	for key, values := range file.Colors {
+		if !validColorKey(key) {
+			return nil, errors.New("unknown colour " + key + ", colours are " + strings.Join(colorKeys, ", ") + " with or without Bold in front")
+		}
+
+		colors[key] = values
+	}
+
+	mode := tb.OutputNormal
+
+	for _, values := range colors {
+		if len(values) > 0 && colorMode(values[0]) > mode {
+			mode = colorMode(values[0])
+		}
+	}
+
+	if mode > depth {
+		mode = depth
+	}
+
+	attributes := map[tb.Attribute]tb.Attribute{}
+
+	for i, key := range colorKeys {
+		for _, bold := range []bool{false, true} {
+			values, ok := colors[boldKey(key, bold)]
+
+			if !ok {
+				values, ok = colors[key]
+			}
+
+			if !ok {
+				values = []string{key}
+			}
+
+			attr, e := resolveColor(key, bold, values, mode)
+
+			if e != nil {
+				return nil, e
+			}
+
+			base := z.AttrColorBlack + tb.Attribute(i)
+
+			if bold {
+				base |= z.AttrBold
+			}
+
+			attributes[base] = attr
+		}
+	}
+
+	set := file.Symbols
+
+	if set == "" {
+		set = z.SYMBOLS_UNICODE
+	}
+
+	glyphs, ok := symbolSets[set]
+
+	if !ok {
+		return nil, errors.New("unknown symbols " + set + ", use " + z.SYMBOLS_UNICODE + " or " + z.SYMBOLS_ASCII)
+	}
+
+	symbols := map[rune]rune{}
+
+	for _, set := range []map[string]string{glyphs, file.Glyphs} {
+		for from, to := range set {
+			f, t := []rune(from), []rune(to)
+
+			if len(f) != 1 || len(t) != 1 {
+				return nil, errors.New("glyph " + from + ": " + to + " must map one character to one character")
+			}
+
+			symbols[f[0]] = t[0]
+		}
+	}
+
+	return zc.NewTheme(mode, attributes, symbols), nil
+}
+
+func paletteNames() []string {
+	names := []string{}
+
+	for name := range palettes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func validColorKey(key string) bool {
+	for _, name := range colorKeys {
+		if key == name || key == boldKey(name, true) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func boldKey(key string, bold bool) string {
+	if bold {
+		return "Bold" + key
+	}
+
+	return key
+}
+
+func colorDepth() tb.OutputMode {
+	colorTerm := strings.ToLower(os.Getenv("COLORTERM"))
+
+	if colorTerm == "truecolor" || colorTerm == "24bit" {
+		return tb.OutputRGB
+	}
+
+	if strings.Contains(os.Getenv("TERM"), "256color") {
+		return tb.Output256
+	}
+
+	return tb.OutputNormal
+}
+
+func colorMode(value string) tb.OutputMode {
+	if strings.HasPrefix(value, "#") {
+		return tb.OutputRGB
+	}
+
+	if _, e := strconv.Atoi(value); e == nil {
+		return tb.Output256
+	}
+
+	return tb.OutputNormal
+}
+
+func resolveColor(key string, bold bool, values []string, mode tb.OutputMode) (tb.Attribute, error) {
+	for _, value := range values {
+		if colorMode(value) > mode {
+			continue
+		}
+
+		if strings.HasPrefix(value, "#") {
+			n, e := strconv.ParseUint(value[1:], 16, 32)
+
+			if e != nil || len(value) != 7 {
+				return 0, errors.New(boldKey(key, bold) + ": colour " + value + " is not #rrggbb")
+			}
+
+			return tb.RGBToAttribute(uint8(n>>16), uint8(n>>8), uint8(n)), nil
+		}
+
+		if n, e := strconv.Atoi(value); e == nil {
+			if n < 0 || n > 255 {
+				return 0, errors.New(boldKey(key, bold) + ": colour " + value + " is not between 0 and 255")
+			}
+
+			if mode == tb.OutputRGB {
+				r, g, b := xtermRGB(n)
+
+				return tb.RGBToAttribute(r, g, b), nil
+			}
+
+			return tb.Attribute(n + 1), nil
+		}
+
+		for i, name := range colorKeys {
+			if !strings.EqualFold(value, name) {
+				continue
+			}
+
+			if mode == tb.OutputRGB {
+				rgb := rgbColors[name][0]
+
+				if bold {
+					rgb = rgbColors[name][1]
+				}
+
+				return tb.RGBToAttribute(rgb[0], rgb[1], rgb[2]), nil
+			}
+
+			return z.AttrColorBlack + tb.Attribute(i), nil
+		}
+
+		return 0, errors.New(boldKey(key, bold) + ": unknown colour " + value + ", use a colour name, a number from 0 to 255 or #rrggbb")
+	}
+
+	return 0, errors.New(boldKey(key, bold) + " has no colour this terminal can show, end the list with a colour name")
+}
+
+func xtermRGB(n int) (uint8, uint8, uint8) {
+	switch {
+	case n < 16:
+		rgb := rgbColors[colorKeys[n%8]][n/8]
+
+		return rgb[0], rgb[1], rgb[2]
+
+	case n < 232:
+		levels := []uint8{0, 95, 135, 175, 215, 255}
+		n -= 16
+
+		return levels[n/36], levels[n/6%6], levels[n%6]
+	}
+
+	level := uint8(8 + (n-232)*10)
+
+	return level, level, level
+}
@@ -1,280 +1,731 @@
-/*
-Zahhak2, a Golang console game.
-Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
-This program is free software: you can redistribute it and/or modify
-it under the terms of the GNU General Public License as published by
-the Free Software Foundation, either version 3 of the License, or
-(at your option) any later version.
-This program is distributed in the hope that it will be useful,
-but WITHOUT ANY WARRANTY; without even the implied warranty of
-MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
-GNU General Public License for more details.
-You should have received a copy of the GNU General Public License
-along with this program.  If not, see <http://www.gnu.org/licenses/>.
-*/
-
-package canvas
-
-import (
-	"fmt"
-	"strconv"
-	"time"
-
-	rw "github.com/mattn/go-runewidth"
-	tb "github.com/nsf/termbox-go"
-
-	z "../common"
-)
-
-type Canvas struct {
-	rooms    z.IRooms
-	statuses z.IRing
-
-	menuWidth      int
-	numMsgsDisplay int
-	worldWidth     int
-	worldHeight    int
-	screenWidth    int
-	screenHeight   int
-}
-
-func NewCanvas(c *z.Config, rooms z.IRooms, statuses z.IRing) *Canvas {
-	defer tb.Flush()
-	tb.Clear(tb.ColorBlack, tb.ColorBlack)
-
-	screenWidth := c.WorldWidth*c.Capacity + c.MenuWidth
-	screenHeight := c.WorldHeight + c.MenuHeight
-
-	return &Canvas{
-		rooms:          rooms,
-		statuses:       statuses,
-		menuWidth:      c.MenuWidth,
-		numMsgsDisplay: c.NumMsgsDisplay,
-		worldWidth:     c.WorldWidth,
-		worldHeight:    c.WorldHeight,
-		screenWidth:    screenWidth,
-		screenHeight:   screenHeight}
-}
-
-func (c *Canvas) Draw(numHealths, numStrengths, numTreasures, totalTreasures int) {
-	tb.Sync()
-	defer tb.Flush()
-
-	c.paint()
-
-	c.stats(numHealths, numStrengths, numTreasures, totalTreasures)
-
-	c.overlay()
-}
-
-func (c *Canvas) print(x, y int, msg string, color tb.Attribute) {
-	c.tbprint(x, y, color, z.ColorBlack, msg)
-}
-
-func (c *Canvas) tbprint(x, y int, fg, bg tb.Attribute, msg string) {
-	for _, c := range msg {
-		tb.SetCell(x, y, c, fg, bg)
-		x += rw.RuneWidth(c)
-	}
-}
-
-func (c *Canvas) stats(numHealths, numStrengths, numTreasures, totalTreasures int) {
-	now := time.Now()
-	t := fmt.Sprintf("%02d:%02d:%02d", now.Hour(), now.Minute(), now.Second())
-	col := c.worldWidth + c.menuWidth - len(t)
-	row := c.screenHeight - 1
-	c.print(col, row, t, z.BoldColorWhite)
-
-	row = 2
-	col = c.worldWidth + 2
-	s := c.entryTextValue("Health", strconv.Itoa(numHealths))
-	c.print(col, row, s, z.BoldColorGreen)
-
-	s = c.entryTextValue("Strength", strconv.Itoa(numStrengths))
-	c.print(col, row+1, s, z.BoldColorCyan)
-
-	s = c.entryTextValue("Treasure", strconv.Itoa(numTreasures)+"/"+strconv.Itoa(totalTreasures))
-	c.print(col, row+2, s, z.BoldColorBlue)
-
-	row = 2 + 3 + c.numMsgsDisplay
-	statuses := c.statuses.Values()
-
-	for _, s := range statuses {
-		status, ok := s.(*z.Status)
-
-		if !ok {
-			continue
-		}
-
-		text := c.entryTextPad(status.Text)
-		c.print(col, row, text, status.Color)
-
-		row--
-	}
-}
-
-func (c *Canvas) overlay() {
-	t := "Zahhak2 by Aryo Pehlewan aryopehlewan@hotmail.com Copyright 2021 License GPLv3"
-	s := c.entryTextLen(t, len(t))
-	c.print(0, 0, s, z.BoldColorWhite)
-
-	col := 0
-	row := c.screenHeight - 1
-
-	t = "☻ : Player"
-	s = c.entryText(t)
-	c.print(col, row-1, s, z.BoldColorYellow)
-	t = "☼ : Monster"
-	s = c.entryText(t)
-	c.print(col, row, s, z.BoldColorRed)
-
-	t = "H : Health"
-	s = c.entryText(t)
-	c.print(col+12, row-1, s, z.BoldColorGreen)
-	t = "S : Strength"
-	s = c.entryText(t)
-	c.print(col+12, row, s, z.BoldColorCyan)
-
-	t = "T : Treasure"
-	s = c.entryText(t)
-	c.print(col+25, row-1, s, z.BoldColorBlue)
-	t = "☺ : Opponent"
-	s = c.entryText(t)
-	c.print(col+25, row, s, z.BoldColorMagenta)
-
-	t = "▲ : Bomb"
-	s = c.entryText(t)
-	c.print(col+38, row-1, s, z.BoldColorWhite)
-	t = "◘ : Portal"
-	s = c.entryText(t)
-	c.print(col+38, row, s, z.BoldColorYellow)
-
-	/*
-		t = "Type 'zahak2 help' for options"
-		col = c.worldWidth + c.menuWidth - len(t)
-		row = c.screenHeight - 2
-		c.print(col, row, t, z.ColorWhite)
-	*/
-	col = c.worldWidth + 2
-	row = c.worldHeight + 1
-
-	s = c.entryText("Enter: Pause")
-	c.print(col, row-3, s, z.BoldColorYellow)
-	s = c.entryText("Esc/Q: Quit")
-	c.print(col, row-2, s, z.BoldColorYellow)
-	s = c.entryText("Arrows/Left mouse: Move")
-	c.print(col, row-1, s, z.BoldColorYellow)
-	s = c.entryText("Space/Right mouse: Shoot")
-	c.print(col, row, s, z.BoldColorYellow)
-
-	row = 1
-
-	for col := 0; col < c.screenWidth; col++ {
-		c.print(col, row, "═", z.BoldColorYellow)
-	}
-
-	row = c.worldHeight + 2
-
-	for col := 0; col < c.screenWidth; col++ {
-		c.print(col, row, "═", z.BoldColorYellow)
-	}
-
-	row = 5
-
-	for col := c.worldWidth + 1; col < c.screenWidth; col++ {
-		c.print(col, row, "═", z.BoldColorYellow)
-	}
-
-	row = 2 + 4 + c.numMsgsDisplay
-
-	for col := c.worldWidth + 1; col < c.screenWidth; col++ {
-		c.print(col, row, "═", z.BoldColorYellow)
-	}
-
-	col = 0
-
-	for row := 1; row < c.screenHeight-2; row++ {
-		c.print(col, row, "║", z.BoldColorYellow)
-	}
-
-	col = c.worldWidth + 1
-
-	for row := 1; row < c.screenHeight-2; row++ {
-		c.print(col, row, "║", z.BoldColorYellow)
-	}
-
-	row = 1
-	col = 0
-	c.print(col, row, "╔", z.BoldColorYellow)
-	row = c.worldHeight + 2
-	col = 0
-	c.print(col, row, "╚", z.BoldColorYellow)
-	row = 1
-	col = c.worldWidth + 1
-	c.print(col, row, "╦", z.BoldColorYellow)
-	row = c.worldHeight + 2
-	col = c.worldWidth + 1
-	c.print(col, row, "╩", z.BoldColorYellow)
-	row = 5
-	col = c.worldWidth + 1
-	c.print(col, row, "╠", z.BoldColorYellow)
-	row = c.worldHeight - 3
-	col = c.worldWidth + 1
-	c.print(col, row, "╠", z.BoldColorYellow)
-}
-
-func (c *Canvas) paint() {
-	for y := 0; y < c.worldHeight; y++ {
-		for x := 0; x < c.worldWidth; x++ {
-			gos := c.rooms.GetGameObjects(x, y)
-			l := len(gos)
-
-			if l == 0 {
-				tb.SetCell(x+1, y+2, '.', z.BoldColorWhite, z.ColorBlack)
-			} else {
-				for i, g := range gos {
-					tb.SetCell(x+1+i, y+2, g.GetSymbol(), g.GetColor(), z.ColorBlack)
-				}
-			}
-		}
-	}
-}
-
-func (c *Canvas) entryText(text string) string {
-	return c.status(text, z.STATUS_LEN)
-}
-
-func (c *Canvas) entryTextPad(text string) string {
-	tp := c.padRight(text, " ", z.STATUS_LEN)
-
-	return c.status(tp, z.STATUS_LEN)
-}
-
-func (c *Canvas) padRight(str, pad string, lenght int) string {
-	for {
-		str += pad
-		if len(str) > lenght {
-			return str[0:lenght]
-		}
-	}
-}
-
-func (c *Canvas) entryTextValue(text, value string) string {
-	tv := fmt.Sprintf("%-8s", text) + " = " + fmt.Sprintf("%-6s", value)
-
-	return c.status(tv, z.STATUS_LEN)
-}
-
-func (c *Canvas) entryTextLen(text string, length int) string {
-	return c.status(text, length)
-}
-
-func (c *Canvas) status(text string, length int) string {
-	symbol := text
-
-	if len(text) > length {
-		symbol = text[0:length]
-	}
-
-	return symbol
-}
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package canvas
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	rw "github.com/mattn/go-runewidth"
+	tb "github.com/nsf/termbox-go"
+
+	z "../common"
+)
+
+type Canvas struct {
+	sync.RWMutex
+
+	rooms    z.IRooms
+	theme    *Theme
+	frames   *frameStats
+	statuses z.IRing
+	chat     z.IChat
+	scroll   int
+	follow   string
+	scores   []*z.Score
+	network  []*z.Status
+	help     []string
+
+	centerX  int
+	centerY  int
+	cameraX  int
+	cameraY  int
+	centered bool
+	aimed    bool
+	minimap  bool
+	explored [][]bool
+	reveal   bool
+	fog      bool
+	vision   int
+	panel    bool
+	legend   bool
+	showHelp bool
+	small    bool
+	resized  bool
+
+	menuWidth      int
+	menuHeight     int
+	numMsgsDisplay int
+	worldWidth     int
+	worldHeight    int
+	viewWidth      int
+	viewHeight     int
+	screenWidth    int
+	screenHeight   int
+}
+
+func NewCanvas(c *z.Config, rooms z.IRooms, statuses z.IRing, chat z.IChat, theme *Theme) *Canvas {
+	defer tb.Flush()
+	tb.SetOutputMode(theme.Mode())
+	tb.Clear(theme.Color(z.ColorBlack), theme.Color(z.ColorBlack))
+
+	explored := make([][]bool, c.WorldHeight)
+
+	for y := range explored {
+		explored[y] = make([]bool, c.WorldWidth)
+	}
+
+	canvas := &Canvas{
+		rooms:          rooms,
+		theme:          theme,
+		frames:         newFrameStats(),
+		minimap:        c.Minimap,
+		explored:       explored,
+		reveal:         !c.Explored && !c.Fog,
+		fog:            c.Fog,
+		vision:         c.Vision,
+		statuses:       statuses,
+		chat:           chat,
+		menuWidth:      c.MenuWidth,
+		menuHeight:     c.MenuHeight,
+		worldWidth:     c.WorldWidth,
+		worldHeight:    c.WorldHeight}
+
+	canvas.layout(tb.Size())
+
+	return canvas
+}
+
+func (c *Canvas) Draw(numHealths, numStrengths, numTreasures, totalTreasures int) {
+	start := time.Now()
+	resized := c.relayout()
+
+	c.clear()
+
+	defer c.present(resized, start)
+
+	if c.tooSmall() {
+		return
+	}
+
+	c.aim()
+
+	c.paint()
+
+	c.stats(numHealths, numStrengths, numTreasures, totalTreasures)
+
+	c.overlay()
+}
+
+func (c *Canvas) print(x, y int, msg string, color tb.Attribute) {
+	c.tbprint(x, y, color, z.ColorBlack, msg)
+}
+
+func (c *Canvas) tbprint(x, y int, fg, bg tb.Attribute, msg string) {
+	for _, r := range msg {
+		r = c.theme.Symbol(r)
+		tb.SetCell(x, y, r, c.theme.Color(fg), c.theme.Color(bg))
+		x += rw.RuneWidth(r)
+	}
+}
+
+func (c *Canvas) cell(x, y int, r rune, color tb.Attribute) {
+	tb.SetCell(x, y, c.theme.Symbol(r), c.theme.Color(color), c.theme.Color(z.ColorBlack))
+}
+
+func (c *Canvas) clear() {
+	tb.Clear(c.theme.Color(z.ColorBlack), c.theme.Color(z.ColorBlack))
+}
+
+func (c *Canvas) stats(numHealths, numStrengths, numTreasures, totalTreasures int) {
+	if !c.panel {
+		c.compact(numHealths, numStrengths, numTreasures, totalTreasures)
+
+		return
+	}
+
+	if c.legend {
+		now := time.Now()
+		t := fmt.Sprintf("%02d:%02d:%02d", now.Hour(), now.Minute(), now.Second())
+		col := c.viewWidth + c.menuWidth - len(t)
+		row := c.screenHeight - 1
+		c.print(col, row, t, z.BoldColorWhite)
+	}
+
+	row := 2
+	col := c.viewWidth + 2
+	s := c.entryTextValue("Health", strconv.Itoa(numHealths))
+	c.print(col, row, s, z.BoldColorGreen)
+
+	s = c.entryTextValue("Strength", strconv.Itoa(numStrengths))
+	c.print(col, row+1, s, z.BoldColorCyan)
+
+	s = c.entryTextValue("Treasure", strconv.Itoa(numTreasures)+"/"+strconv.Itoa(totalTreasures))
+	c.print(col, row+2, s, z.BoldColorBlue)
+
+	row = 2 + 3 + c.numMsgsDisplay
+	top := 6
+	statuses := c.statuses.Values()
+
+	if height := c.minimapHeight(); height > 0 {
+		c.drawMinimap(col, top, height)
+		top += height + 1
+	}
+
+	if c.getScroll() > 0 {
+		statuses = c.history()
+	}
+
+	if scores := c.getScores(); scores != nil {
+		statuses = c.scoreboard(scores)
+	}
+
+	if network := c.getDiagnostics(); network != nil {
+		statuses = c.diagnostics(network)
+	}
+
+	if n := row - top + 1; len(statuses) > n {
+		statuses = statuses[len(statuses)-n:]
+	}
+
+	for _, s := range statuses {
+		status, ok := s.(*z.Status)
+
+		if !ok {
+			continue
+		}
+
+		text := c.entryTextPad(status.Text)
+		c.print(col, row, text, status.Color)
+
+		row--
+	}
+}
+
+func (c *Canvas) overlay() {
+	if c.panel {
+		t := "Zahhak2 by Aryo Pehlewan aryopehlewan@hotmail.com Copyright 2021 License GPLv3"
+		s := c.entryTextLen(t, len(t))
+		c.print(0, 0, s, z.BoldColorWhite)
+	}
+
+	if c.legend {
+		c.drawLegend()
+	}
+
+	c.borders()
+
+	if !c.panel {
+		return
+	}
+
+	col := c.viewWidth + 2
+	row := c.viewHeight + 1
+
+	if c.chat.Opened() {
+		c.input(col, row-3)
+	} else if c.showHelp {
+		for i, line := range c.getHelp() {
+			if i > 3 {
+				break
+			}
+
+			s := c.entryText(line)
+			c.print(col, row-3+i, s, z.BoldColorYellow)
+		}
+	}
+
+	c.panelBorders()
+}
+
+func (c *Canvas) drawLegend() {
+	col := 0
+	row := c.screenHeight - 1
+
+	t := "☻ : Player"
+	s := c.entryText(t)
+	c.print(col, row-1, s, z.BoldColorYellow)
+	t = "☼ : Monster"
+	s = c.entryText(t)
+	c.print(col, row, s, z.BoldColorRed)
+
+	t = "H : Health"
+	s = c.entryText(t)
+	c.print(col+12, row-1, s, z.BoldColorGreen)
+	t = "S : Strength"
+	s = c.entryText(t)
+	c.print(col+12, row, s, z.BoldColorCyan)
+
+	t = "T : Treasure"
+	s = c.entryText(t)
+	c.print(col+25, row-1, s, z.BoldColorBlue)
+	t = "☺ : Opponent"
+	s = c.entryText(t)
+	c.print(col+25, row, s, z.BoldColorMagenta)
+
+	t = "▲ : Bomb"
+	s = c.entryText(t)
+	c.print(col+38, row-1, s, z.BoldColorWhite)
+	t = "◘ : Portal"
+	s = c.entryText(t)
+	c.print(col+38, row, s, z.BoldColorYellow)
+
+	if c.panel {
+		t = "Type 'zahhak2 help' for options"
+		col = c.viewWidth + c.menuWidth - len(t)
+		row = c.screenHeight - 2
+		c.print(col, row, t, z.ColorWhite)
+	}
+}
+
+func (c *Canvas) borders() {
+	row := 1
+
+	for col := 0; col < c.screenWidth; col++ {
+		c.print(col, row, "═", z.BoldColorYellow)
+	}
+
+	row = c.viewHeight + 2
+
+	for col := 0; col < c.screenWidth; col++ {
+		c.print(col, row, "═", z.BoldColorYellow)
+	}
+
+	col := 0
+
+	for row := 1; row <= c.viewHeight+2; row++ {
+		c.print(col, row, "║", z.BoldColorYellow)
+	}
+
+	col = c.viewWidth + 1
+
+	for row := 1; row <= c.viewHeight+2; row++ {
+		c.print(col, row, "║", z.BoldColorYellow)
+	}
+
+	row = 1
+	col = 0
+	c.print(col, row, "╔", z.BoldColorYellow)
+	row = c.viewHeight + 2
+	col = 0
+	c.print(col, row, "╚", z.BoldColorYellow)
+
+	if !c.panel {
+		row = 1
+		col = c.viewWidth + 1
+		c.print(col, row, "╗", z.BoldColorYellow)
+		row = c.viewHeight + 2
+		col = c.viewWidth + 1
+		c.print(col, row, "╝", z.BoldColorYellow)
+
+		return
+	}
+
+	row = 1
+	col = c.viewWidth + 1
+	c.print(col, row, "╦", z.BoldColorYellow)
+	row = c.viewHeight + 2
+	col = c.viewWidth + 1
+	c.print(col, row, "╩", z.BoldColorYellow)
+}
+
+func (c *Canvas) panelBorders() {
+	row := 5
+
+	for col := c.viewWidth + 1; col < c.screenWidth; col++ {
+		c.print(col, row, "═", z.BoldColorYellow)
+	}
+
+	if c.showHelp {
+		row = 2 + 4 + c.numMsgsDisplay
+
+		for col := c.viewWidth + 1; col < c.screenWidth; col++ {
+			c.print(col, row, "═", z.BoldColorYellow)
+		}
+	}
+
+	if height := c.minimapHeight(); height > 0 {
+		row = 6 + height
+
+		for col := c.viewWidth + 1; col < c.screenWidth; col++ {
+			c.print(col, row, "═", z.BoldColorYellow)
+		}
+	}
+
+	row = 5
+	col := c.viewWidth + 1
+	c.print(col, row, "╠", z.BoldColorYellow)
+
+	if c.showHelp {
+		row = c.viewHeight - 3
+		col = c.viewWidth + 1
+		c.print(col, row, "╠", z.BoldColorYellow)
+	}
+
+	if height := c.minimapHeight(); height > 0 {
+		row = 6 + height
+		col = c.viewWidth + 1
+		c.print(col, row, "╠", z.BoldColorYellow)
+	}
+}
+
+func (c *Canvas) input(col, row int) {
+	hint := "Enter: Send / Esc: Cancel"
+	prompt := c.chat.Prompt()
+
+	if prompt != "" {
+		hint = "Enter: OK / Esc: Cancel"
+	}
+
+	s := c.entryTextPad(hint)
+	c.print(col, row, s, z.BoldColorYellow)
+
+	lines := []rune(prompt + "> " + c.chat.Input() + "_")
+
+	for i := 0; i < 3; i++ {
+		line := ""
+
+		if len(lines) > 0 {
+			n := z.STATUS_LEN
+
+			if len(lines) < n {
+				n = len(lines)
+			}
+
+			line, lines = string(lines[:n]), lines[n:]
+		}
+
+		s = c.entryTextPad(line)
+		c.print(col, row+1+i, s, z.BoldColorWhite)
+	}
+}
+
+func (c *Canvas) Scroll(pages int) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.scroll += pages
+
+	n := len(c.chat.History().Values())
+	page := c.page()
+	max := (n + page - 1) / page
+
+	if c.scroll > max {
+		c.scroll = max
+	}
+
+	if c.scroll < 0 {
+		c.scroll = 0
+	}
+}
+
+func (c *Canvas) SetRooms(rooms z.IRooms) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.rooms = rooms
+
+	for y := range c.explored {
+		for x := range c.explored[y] {
+			c.explored[y][x] = false
+		}
+	}
+}
+
+func (c *Canvas) getRooms() z.IRooms {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.rooms
+}
+
+func (c *Canvas) Follow(id string) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.follow = id
+}
+
+func (c *Canvas) getFollow() string {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.follow
+}
+
+func (c *Canvas) Scoreboard(scores []*z.Score) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.scores = scores
+}
+
+func (c *Canvas) getScores() []*z.Score {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.scores
+}
+
+func (c *Canvas) Help(lines []string) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.help = lines
+}
+
+func (c *Canvas) getHelp() []string {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.help
+}
+
+func (c *Canvas) scoreboard(scores []*z.Score) []interface{} {
+	lines := []interface{}{}
+
+	if len(scores) > c.page() {
+		scores = scores[:c.page()]
+	}
+
+	for i := len(scores) - 1; i >= 0; i-- {
+		s := scores[i]
+		text := fmt.Sprintf("%-11s %4d %4d %4d", c.status(s.Name, 11), s.Treasure, s.Health, s.Strength)
+		lines = append(lines, &z.Status{Text: text, Color: s.Color})
+	}
+
+	text := fmt.Sprintf("%-11s %4s %4s %4s", "Name", "Tre", "Hea", "Str")
+	title := &z.Status{Text: text, Color: z.BoldColorWhite}
+
+	return append(lines, title)
+}
+
+func (c *Canvas) Diagnostics(lines []*z.Status) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.network = lines
+}
+
+func (c *Canvas) getDiagnostics() []*z.Status {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.network
+}
+
+func (c *Canvas) diagnostics(network []*z.Status) []interface{} {
+	lines := []interface{}{}
+
+	if len(network) > c.page()+1 {
+		network = network[:c.page()+1]
+	}
+
+	for i := len(network) - 1; i >= 0; i-- {
+		lines = append(lines, network[i])
+	}
+
+	return lines
+}
+
+func (c *Canvas) page() int {
+	if c.numMsgsDisplay < 2 {
+		return 1
+	}
+
+	return c.numMsgsDisplay - 1
+}
+
+func (c *Canvas) getScroll() int {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.scroll
+}
+
+func (c *Canvas) history() []interface{} {
+	values := c.chat.History().Values()
+	page := c.page()
+	end := len(values) - (c.getScroll()-1)*page
+	start := end - page
+
+	if start < 0 {
+		start = 0
+	}
+
+	if end > len(values) {
+		end = len(values)
+	}
+
+	title := &z.Status{Text: "Chat log (PgDn to return)", Color: z.BoldColorWhite}
+
+	return append(values[start:end:end], title)
+}
+
+func (c *Canvas) Center(x, y int) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.centerX, c.centerY = x, y
+	c.centered = true
+}
+
+func (c *Canvas) ToWorld(x, y int) (int, int, bool) {
+	c.RLock()
+	defer c.RUnlock()
+
+	x, y = x-1, y-2
+
+	if c.small || x < 0 || y < 0 || x >= c.viewWidth || y >= c.viewHeight {
+		return 0, 0, false
+	}
+
+	return x + c.cameraX, y + c.cameraY, true
+}
+
+func (c *Canvas) getCamera() (int, int) {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.cameraX, c.cameraY
+}
+
+func (c *Canvas) aim() {
+	c.Lock()
+	defer c.Unlock()
+
+	if !c.centered {
+		return
+	}
+
+	if !c.aimed {
+		c.cameraX = c.clamp(c.centerX-c.viewWidth/2, c.worldWidth-c.viewWidth)
+		c.cameraY = c.clamp(c.centerY-c.viewHeight/2, c.worldHeight-c.viewHeight)
+		c.aimed = true
+
+		c.explore()
+
+		return
+	}
+
+	c.cameraX = c.pan(c.cameraX, c.centerX, c.viewWidth, c.worldWidth)
+	c.cameraY = c.pan(c.cameraY, c.centerY, c.viewHeight, c.worldHeight)
+
+	c.explore()
+}
+
+func (c *Canvas) pan(camera, center, view, world int) int {
+	margin := view / z.CAMERA_MARGIN
+	target := camera
+
+	if center < camera+margin {
+		target = center - margin
+	} else if center >= camera+view-margin {
+		target = center - view + margin + 1
+	}
+
+	target = c.clamp(target, world-view)
+	d := target - camera
+
+	if d > view/2 || d < -view/2 {
+		return target
+	}
+
+	if d > z.CAMERA_SPEED {
+		d = z.CAMERA_SPEED
+	} else if d < -z.CAMERA_SPEED {
+		d = -z.CAMERA_SPEED
+	}
+
+	return camera + d
+}
+
+func (c *Canvas) clamp(n, max int) int {
+	if n > max {
+		n = max
+	}
+
+	if n < 0 {
+		n = 0
+	}
+
+	return n
+}
+
+func (c *Canvas) paint() {
+	follow := c.getFollow()
+	rooms := c.getRooms()
+	cameraX, cameraY := c.getCamera()
+	centerX, centerY, centered := c.getCenter()
+
+	for y := 0; y < c.viewHeight; y++ {
+		for x := 0; x < c.viewWidth; x++ {
+			if !c.visible(centerX, centerY, centered, cameraX+x, cameraY+y) {
+				c.paintFog(x, y, cameraX+x, cameraY+y)
+
+				continue
+			}
+
+			gos := rooms.GetGameObjects(cameraX+x, cameraY+y)
+			l := len(gos)
+
+			if l == 0 {
+				c.cell(x+1, y+2, '.', z.BoldColorWhite)
+			} else {
+				for i, g := range gos {
+					color := g.GetColor()
+
+					if follow != "" && g.GetID() == follow {
+						color |= z.AttrReverse
+					}
+
+					c.cell(x+1+i, y+2, g.GetSymbol(), color)
+				}
+			}
+		}
+	}
+}
+
+func (c *Canvas) entryText(text string) string {
+	return c.status(text, z.STATUS_LEN)
+}
+
+func (c *Canvas) entryTextPad(text string) string {
+	tp := c.padRight(text, " ", z.STATUS_LEN)
+
+	return c.status(tp, z.STATUS_LEN)
+}
+
+func (c *Canvas) padRight(str, pad string, lenght int) string {
+	for {
+		str += pad
+		if len(str) > lenght {
+			return str[0:lenght]
+		}
+	}
+}
+
+func (c *Canvas) entryTextValue(text, value string) string {
+	tv := fmt.Sprintf("%-8s", text) + " = " + fmt.Sprintf("%-6s", value)
+
+	return c.status(tv, z.STATUS_LEN)
+}
+
+func (c *Canvas) entryTextLen(text string, length int) string {
+	return c.status(text, length)
+}
+
+func (c *Canvas) status(text string, length int) string {
+	symbol := text
+
+	if len(text) > length {
+		symbol = text[0:length]
+	}
+
+	return symbol
+}
@@ -0,0 +1,52 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package canvas
+
+import (
+	z "../common"
+)
+
+func (c *Canvas) getCenter() (int, int, bool) {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.centerX, c.centerY, c.centered
+}
+
+func (c *Canvas) visible(centerX, centerY int, centered bool, x, y int) bool {
+	if !c.fog || !centered {
+		return true
+	}
+
+	return z.Visible(centerX, centerY, x, y, c.vision, c.worldWidth, c.worldHeight, nil)
+}
+
+func (c *Canvas) remembered(x, y int) bool {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.explored[y][x]
+}
+
+func (c *Canvas) paintFog(x, y, worldX, worldY int) {
+	symbol := ' '
+
+	if c.remembered(worldX, worldY) {
+		symbol = '.'
+	}
+
+	c.cell(x+1, y+2, symbol, z.BoldColorBlack)
+}
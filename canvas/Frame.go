@@ -0,0 +1,144 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package canvas
+
+import (
+	"sync"
+	"time"
+
+	tb "github.com/nsf/termbox-go"
+
+	z "../common"
+)
+
+type Frames struct {
+	Rate    int
+	Average time.Duration
+	Slowest time.Duration
+	Cells   int
+	Skipped int
+	Syncs   int64
+}
+
+type frameStats struct {
+	sync.Mutex
+
+	previous []tb.Cell
+	synced   time.Time
+	syncs    int64
+	count    int
+	skipped  int
+	cells    int
+	spent    time.Duration
+	slowest  time.Duration
+	sampled  time.Time
+	rate     Frames
+}
+
+func newFrameStats() *frameStats {
+	return &frameStats{synced: time.Now(), sampled: time.Now()}
+}
+
+func (f *frameStats) diff(cells []tb.Cell) int {
+	changed := 0
+
+	if len(cells) != len(f.previous) {
+		changed = len(cells)
+		f.previous = make([]tb.Cell, len(cells))
+	} else {
+		for i := range cells {
+			if cells[i] != f.previous[i] {
+				changed++
+			}
+		}
+	}
+
+	copy(f.previous, cells)
+
+	return changed
+}
+
+func (f *frameStats) stale() bool {
+	return time.Since(f.synced) >= z.FRAME_SYNC
+}
+
+func (f *frameStats) sync() {
+	f.synced = time.Now()
+	f.syncs++
+}
+
+func (f *frameStats) record(spent time.Duration, changed int) {
+	f.Lock()
+	defer f.Unlock()
+
+	f.count++
+	f.cells += changed
+	f.spent += spent
+
+	if changed == 0 {
+		f.skipped++
+	}
+
+	if spent > f.slowest {
+		f.slowest = spent
+	}
+}
+
+func (f *frameStats) Frames() Frames {
+	f.Lock()
+	defer f.Unlock()
+
+	elapsed := time.Since(f.sampled)
+
+	if elapsed < time.Second || f.count == 0 {
+		f.rate.Syncs = f.syncs
+
+		return f.rate
+	}
+
+	f.rate = Frames{
+		Rate:    int((int64(f.count)*int64(time.Second) + int64(elapsed)/2) / int64(elapsed)),
+		Average: f.spent / time.Duration(f.count),
+		Slowest: f.slowest,
+		Cells:   f.cells / f.count,
+		Skipped: f.skipped,
+		Syncs:   f.syncs,
+	}
+
+	f.count, f.skipped, f.cells, f.spent, f.slowest = 0, 0, 0, 0, 0
+	f.sampled = time.Now()
+
+	return f.rate
+}
+
+func (c *Canvas) Frames() Frames {
+	return c.frames.Frames()
+}
+
+func (c *Canvas) present(full bool, start time.Time) {
+	changed := c.frames.diff(tb.CellBuffer())
+
+	switch {
+	case full || c.frames.stale():
+		tb.Sync()
+		c.frames.sync()
+
+	case changed > 0:
+		tb.Flush()
+	}
+
+	c.frames.record(time.Since(start), changed)
+}
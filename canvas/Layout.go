@@ -0,0 +1,125 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package canvas
+
+import (
+	"fmt"
+	"strconv"
+
+	z "../common"
+)
+
+func (c *Canvas) Resize(width, height int) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.layout(width, height)
+}
+
+func (c *Canvas) Messages() int {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.numMsgsDisplay
+}
+
+func (c *Canvas) layout(width, height int) {
+	c.panel = width-c.menuWidth-1 >= c.clamp(z.LAYOUT_VIEW_WIDTH, c.worldWidth)
+	c.legend = height-c.menuHeight >= c.clamp(z.LAYOUT_VIEW_HEIGHT, c.worldHeight)
+
+	c.viewWidth = c.clamp(width-2, c.worldWidth)
+	c.screenWidth = c.viewWidth + 2
+
+	if c.panel {
+		c.viewWidth = c.clamp(width-c.menuWidth-1, c.worldWidth)
+		c.screenWidth = c.viewWidth + c.menuWidth + 1
+	}
+
+	c.viewHeight = c.clamp(height-3, c.worldHeight)
+	c.screenHeight = c.viewHeight + 3
+
+	if c.legend {
+		c.viewHeight = c.clamp(height-c.menuHeight, c.worldHeight)
+		c.screenHeight = c.viewHeight + c.menuHeight
+	}
+
+	c.showHelp = c.panel && c.viewHeight >= z.LAYOUT_HELP_HEIGHT
+	c.numMsgsDisplay = c.viewHeight - 4
+
+	if c.showHelp {
+		c.numMsgsDisplay -= 5
+	}
+
+	if c.numMsgsDisplay < 1 {
+		c.numMsgsDisplay = 1
+	}
+
+	c.small = c.viewWidth < c.clamp(z.LAYOUT_MIN_WIDTH, c.worldWidth) || c.viewHeight < c.clamp(z.LAYOUT_MIN_HEIGHT, c.worldHeight)
+	c.aimed = false
+	c.resized = true
+}
+
+func (c *Canvas) relayout() bool {
+	c.Lock()
+	defer c.Unlock()
+
+	resized := c.resized
+	c.resized = false
+
+	return resized
+}
+
+func (c *Canvas) tooSmall() bool {
+	c.RLock()
+	defer c.RUnlock()
+
+	if !c.small {
+		return false
+	}
+
+	width := c.clamp(z.LAYOUT_MIN_WIDTH, c.worldWidth) + 2
+	height := c.clamp(z.LAYOUT_MIN_HEIGHT, c.worldHeight) + 3
+
+	c.print(0, 0, "Too small", z.BoldColorRed)
+	c.print(0, 1, "Need "+strconv.Itoa(width)+"x"+strconv.Itoa(height), z.BoldColorWhite)
+	c.print(0, 2, "Esc/Q: Quit", z.BoldColorYellow)
+
+	return true
+}
+
+func (c *Canvas) compact(numHealths, numStrengths, numTreasures, totalTreasures int) {
+	text := fmt.Sprintf("H %d S %d T %d/%d", numHealths, numStrengths, numTreasures, totalTreasures)
+	color := z.BoldColorWhite
+
+	if statuses := c.statuses.Values(); len(statuses) > 0 {
+		if status, ok := statuses[len(statuses)-1].(*z.Status); ok && status.Text != "" {
+			text += " " + status.Text
+			color = status.Color
+		}
+	}
+
+	if c.chat.Opened() {
+		line := []rune(c.chat.Prompt() + "> " + c.chat.Input() + "_")
+
+		if len(line) > c.screenWidth {
+			line = line[len(line)-c.screenWidth:]
+		}
+
+		text, color = string(line), z.BoldColorWhite
+	}
+
+	c.print(0, 0, c.padRight(text, " ", c.screenWidth), color)
+}
@@ -0,0 +1,165 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package canvas
+
+import (
+	tb "github.com/nsf/termbox-go"
+
+	z "../common"
+)
+
+type region struct {
+	seen      bool
+	center    bool
+	players   int
+	monsters  int
+	treasures int
+	portals   int
+}
+
+func (c *Canvas) Minimap(show bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.minimap = show
+}
+
+func (c *Canvas) minimapHeight() int {
+	c.RLock()
+	defer c.RUnlock()
+
+	if !c.panel || !c.minimap || c.scroll > 0 || c.scores != nil || c.network != nil {
+		return 0
+	}
+
+	height := z.MINIMAP_HEIGHT
+
+	if c.worldHeight < height {
+		height = c.worldHeight
+	}
+
+	if c.numMsgsDisplay-height-1 < 3 {
+		return 0
+	}
+
+	return height
+}
+
+func (c *Canvas) explore() {
+	for y := c.cameraY; y < c.cameraY+c.viewHeight && y < c.worldHeight; y++ {
+		for x := c.cameraX; x < c.cameraX+c.viewWidth && x < c.worldWidth; x++ {
+			if c.visible(c.centerX, c.centerY, c.centered, x, y) {
+				c.explored[y][x] = true
+			}
+		}
+	}
+}
+
+func (c *Canvas) drawMinimap(col, row, height int) {
+	width := z.STATUS_LEN
+
+	if c.worldWidth < width {
+		width = c.worldWidth
+	}
+
+	regionWidth := (c.worldWidth + width - 1) / width
+	regionHeight := (c.worldHeight + height - 1) / height
+
+	for y := 0; y < height; y++ {
+		for x := 0; x*regionWidth < c.worldWidth; x++ {
+			r := c.region(x*regionWidth, y*regionHeight, regionWidth, regionHeight)
+			symbol, color := c.regionSymbol(r)
+
+			c.print(col+x, row+y, string(symbol), color)
+		}
+	}
+}
+
+func (c *Canvas) region(left, top, width, height int) *region {
+	rooms := c.getRooms()
+
+	c.RLock()
+	defer c.RUnlock()
+
+	r := &region{}
+
+	for y := top; y < top+height && y < c.worldHeight; y++ {
+		for x := left; x < left+width && x < c.worldWidth; x++ {
+			if c.centered && x == c.centerX && y == c.centerY {
+				r.center = true
+			}
+
+			if !c.reveal && !c.explored[y][x] {
+				continue
+			}
+
+			r.seen = true
+			visible := c.visible(c.centerX, c.centerY, c.centered, x, y)
+
+			for _, g := range rooms.GetGameObjects(x, y) {
+				switch g.GetClass() {
+				case "Player":
+					if visible {
+						r.players++
+					}
+
+				case "Monster":
+					if visible {
+						r.monsters++
+					}
+
+				case "Treasure":
+					r.treasures++
+
+				case "Portal":
+					r.portals++
+				}
+			}
+		}
+	}
+
+	return r
+}
+
+func (c *Canvas) regionSymbol(r *region) (rune, tb.Attribute) {
+	switch {
+	case r.center:
+		return '☻', z.BoldColorYellow
+
+	case !r.seen:
+		return ' ', z.ColorBlack
+
+	case r.players > 0:
+		return '☺', z.BoldColorMagenta
+
+	case r.portals > 0:
+		return '◘', z.BoldColorYellow
+
+	case r.treasures > 0:
+		return 'T', z.BoldColorBlue
+
+	case r.monsters > 3:
+		return '▓', z.BoldColorRed
+
+	case r.monsters > 1:
+		return '▒', z.BoldColorRed
+
+	case r.monsters > 0:
+		return '░', z.BoldColorRed
+	}
+
+	return '·', z.ColorWhite
+}
@@ -0,0 +1,68 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package canvas
+
+import (
+	tb "github.com/nsf/termbox-go"
+
+	z "../common"
+)
+
+const colorMask tb.Attribute = 0x1FF
+
+type Theme struct {
+	mode    tb.OutputMode
+	colors  map[tb.Attribute]tb.Attribute
+	symbols map[rune]rune
+}
+
+func NewTheme(mode tb.OutputMode, colors map[tb.Attribute]tb.Attribute, symbols map[rune]rune) *Theme {
+	return &Theme{mode: mode, colors: colors, symbols: symbols}
+}
+
+func (t *Theme) Mode() tb.OutputMode {
+	if t == nil {
+		return tb.OutputNormal
+	}
+
+	return t.mode
+}
+
+func (t *Theme) Color(attr tb.Attribute) tb.Attribute {
+	if t == nil {
+		return attr
+	}
+
+	color, ok := t.colors[attr&(colorMask|z.AttrBold)]
+
+	if !ok {
+		return attr
+	}
+
+	return color | attr&^colorMask
+}
+
+func (t *Theme) Symbol(r rune) rune {
+	if t == nil {
+		return r
+	}
+
+	if symbol, ok := t.symbols[r]; ok {
+		return symbol
+	}
+
+	return r
+}
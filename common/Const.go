@@ -1,86 +1,226 @@
-/*
-Zahhak2, a Golang console game.
-Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
-This program is free software: you can redistribute it and/or modify
-it under the terms of the GNU General Public License as published by
-the Free Software Foundation, either version 3 of the License, or
-(at your option) any later version.
-This program is distributed in the hope that it will be useful,
-but WITHOUT ANY WARRANTY; without even the implied warranty of
-MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
-GNU General Public License for more details.
-You should have received a copy of the GNU General Public License
-along with this program.  If not, see <http://www.gnu.org/licenses/>.
-*/
-
-package common
-
-import (
-	tb "github.com/nsf/termbox-go"
-)
-
-const (
-	HOST_IP       = "127.0.0.1"
-	PORT_NUM      = "1947"
-	NAME          = "Player"
-	DIFFICULTY    = 30
-	WORLD_WIDTH   = 20
-	WORLD_HEIGHT  = 20
-	NUM_MONSTERS  = 5
-	NUM_HEALTHS   = 10
-	NUM_STRENGTHS = 10
-	NUM_TREASURES = 10
-	NUM_BOMBS     = 10
-	NUM_PORTALS   = 10
-	CAPACITY      = 2
-	VOLUME        = 10
-	DYNAMIC       = true
-)
-
-const (
-	MENU_WIDTH       = 30
-	MENU_HEIGHT      = 5
-	MAX_MSGS_DISPLAY = 9
-	STATUS_LEN       = 29
-	STRENGTH_LOST    = -1
-)
-
-const (
-	AttrBold tb.Attribute = 1 << (iota + 9)
-	AttrUnderline
-	AttrReverse
-)
-
-const (
-	AttrColorDefault tb.Attribute = iota
-	AttrColorBlack
-	AttrColorRed
-	AttrColorGreen
-	AttrColorYellow
-	AttrColorBlue
-	AttrColorMagenta
-	AttrColorCyan
-	AttrColorWhite
-)
-
-const (
-	ColorBlack   = AttrColorBlack & ^AttrBold
-	ColorRed     = AttrColorRed & ^AttrBold
-	ColorGreen   = AttrColorGreen & ^AttrBold
-	ColorYellow  = AttrColorYellow & ^AttrBold
-	ColorBlue    = AttrColorBlue & ^AttrBold
-	ColorMagenta = AttrColorMagenta & ^AttrBold
-	ColorCyan    = AttrColorCyan & ^AttrBold
-	ColorWhite   = AttrColorWhite & ^AttrBold
-)
-
-const (
-	BoldColorBlack   = AttrColorBlack | AttrBold
-	BoldColorRed     = AttrColorRed | AttrBold
-	BoldColorGreen   = AttrColorGreen | AttrBold
-	BoldColorYellow  = AttrColorYellow | AttrBold
-	BoldColorBlue    = AttrColorBlue | AttrBold
-	BoldColorMagenta = AttrColorMagenta | AttrBold
-	BoldColorCyan    = AttrColorCyan | AttrBold
-	BoldColorWhite   = AttrColorWhite | AttrBold
-)
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package common
+
+import (
+	"time"
+
+	tb "github.com/nsf/termbox-go"
+)
+
+const (
+	HOST_IP        = "127.0.0.1"
+	BIND_ADDR      = ""
+	PORT_NUM       = "1947"
+	NAME           = "Player"
+	DIFFICULTY     = 30
+	WORLD_WIDTH    = 20
+	WORLD_HEIGHT   = 20
+	NUM_MONSTERS   = 5
+	NUM_HEALTHS    = 10
+	NUM_STRENGTHS  = 10
+	NUM_TREASURES  = 10
+	NUM_BOMBS      = 10
+	NUM_PORTALS    = 10
+	CAPACITY       = 2
+	VOLUME         = 10
+	MOVEMENT       = MOVE_WALK
+	DIAGONAL       = false
+	DYNAMIC        = true
+	LOBBY          = true
+	JOIN_MODE      = JOIN_SPECTATOR
+	ROUNDS         = 0
+	DISCOVERY      = true
+	GAME_NAME      = "Zahhak2"
+	MATCH_NAME     = "Main"
+	MAX_MATCHES    = 8
+	MATCH_NAME_LEN = 16
+	CERT_FILE      = "zahhak2.crt"
+	KEY_FILE       = "zahhak2.key"
+	ROUND_DELAY    = 10 * time.Second
+)
+
+const (
+	NAME_LEN = 16
+)
+
+const (
+	REPLAY_VERSION   = 1
+	REPLAY_TICK      = 50 * time.Millisecond
+	REPLAY_SEEK      = 10 * time.Second
+	REPLAY_MIN_SPEED = 0.25
+	REPLAY_MAX_SPEED = 8
+)
+
+const (
+	CONFIG_DIR  = "zahhak2"
+	CONFIG_FILE = "config.json"
+	KEYMAP_FILE = "keymap.json"
+	THEME_FILE  = "theme.json"
+	ENV_PREFIX  = "ZAHHAK2_"
+)
+
+const (
+	SAVE_VERSION    = 2
+	SAVE_DIR        = "saves"
+	SAVE_EXT        = ".json"
+	AUTOSAVE_SLOT   = "autosave"
+	AUTOSAVE_PERIOD = 1 * time.Minute
+	QUICKSAVE_SLOT  = "quicksave"
+)
+
+const (
+	CHEAT_STRIKES   = 5
+	CHEAT_PERIOD    = 10 * time.Second
+	MOVE_TOLERANCE  = 2
+	MISSLE_STRENGTH = 50
+)
+
+const (
+	REJECT_PASSWORD = "BadPassword"
+	REJECT_TOKEN    = "BadToken"
+	REJECT_NAME     = "InvalidName"
+	REJECT_TAKEN    = "NameTaken"
+	REJECT_BANNED   = "Banned"
+	REJECT_STARTED  = "MatchStarted"
+	REJECT_MATCH    = "NoMatch"
+	REJECT_FULL     = "ServerFull"
+	REJECT_KICKED   = "Kicked"
+	REJECT_CHEAT    = "Cheating"
+	REJECT_FLOOD    = "Flooding"
+)
+
+const (
+	PHASE_LOBBY      = "Lobby"
+	PHASE_PLAYING    = "Playing"
+	PHASE_SPECTATING = "Spectating"
+)
+
+const (
+	MOVE_WALK        = "Walk"
+	MOVE_STEP        = "Step"
+	MOVE_HOLD        = "Hold"
+	MOVE_HOLD_TIME   = 600 * time.Millisecond
+	MOVE_HOLD_REPEAT = 200 * time.Millisecond
+)
+
+const (
+	JOIN_DISABLED  = "Disabled"
+	JOIN_SPECTATOR = "Spectator"
+	JOIN_PLAY      = "Play"
+)
+
+const (
+	MENU_WIDTH       = 30
+	MENU_HEIGHT      = 5
+	MAX_MSGS_DISPLAY = 9
+	STATUS_LEN       = 29
+	STRENGTH_LOST    = -1
+)
+
+const (
+	FPS        = 10
+	FPS_MAX    = 60
+	FRAME_SYNC = 5 * time.Second
+)
+
+const (
+	SYMBOLS_UNICODE = "Unicode"
+	SYMBOLS_ASCII   = "ASCII"
+)
+
+const (
+	LAYOUT_VIEW_WIDTH  = 30
+	LAYOUT_VIEW_HEIGHT = 12
+	LAYOUT_HELP_HEIGHT = 14
+	LAYOUT_MIN_WIDTH   = 16
+	LAYOUT_MIN_HEIGHT  = 6
+)
+
+const (
+	CAMERA_MARGIN = 4
+	CAMERA_SPEED  = 2
+)
+
+const (
+	FOG       = false
+	VISION    = 8
+	FOG_SWEEP = 250 * time.Millisecond
+)
+
+const (
+	MINIMAP          = true
+	MINIMAP_EXPLORED = false
+	MINIMAP_HEIGHT   = 8
+)
+
+const (
+	CHAT_HISTORY = 100
+	CHAT_LEN     = 60
+	CHAT_RATE    = 5
+	CHAT_PERIOD  = 10 * time.Second
+)
+
+const (
+	CHAT_ALL     = "All"
+	CHAT_TEAM    = "Team"
+	CHAT_WHISPER = "Whisper"
+)
+
+const (
+	AttrBold tb.Attribute = 1 << (iota + 9)
+	AttrUnderline
+	AttrReverse
+)
+
+const (
+	AttrColorDefault tb.Attribute = iota
+	AttrColorBlack
+	AttrColorRed
+	AttrColorGreen
+	AttrColorYellow
+	AttrColorBlue
+	AttrColorMagenta
+	AttrColorCyan
+	AttrColorWhite
+)
+
+const (
+	ColorBlack   = AttrColorBlack & ^AttrBold
+	ColorRed     = AttrColorRed & ^AttrBold
+	ColorGreen   = AttrColorGreen & ^AttrBold
+	ColorYellow  = AttrColorYellow & ^AttrBold
+	ColorBlue    = AttrColorBlue & ^AttrBold
+	ColorMagenta = AttrColorMagenta & ^AttrBold
+	ColorCyan    = AttrColorCyan & ^AttrBold
+	ColorWhite   = AttrColorWhite & ^AttrBold
+)
+
+const (
+	BoldColorBlack   = AttrColorBlack | AttrBold
+	BoldColorRed     = AttrColorRed | AttrBold
+	BoldColorGreen   = AttrColorGreen | AttrBold
+	BoldColorYellow  = AttrColorYellow | AttrBold
+	BoldColorBlue    = AttrColorBlue | AttrBold
+	BoldColorMagenta = AttrColorMagenta | AttrBold
+	BoldColorCyan    = AttrColorCyan | AttrBold
+	BoldColorWhite   = AttrColorWhite | AttrBold
+)
+
+const (
+	PLAYER_COLOR   = BoldColorYellow
+	OPPONENT_COLOR = BoldColorMagenta
+)
@@ -1,28 +1,28 @@
-/*
-Zahhak2, a Golang console game.
-Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
-This program is free software: you can redistribute it and/or modify
-it under the terms of the GNU General Public License as published by
-the Free Software Foundation, either version 3 of the License, or
-(at your option) any later version.
-This program is distributed in the hope that it will be useful,
-but WITHOUT ANY WARRANTY; without even the implied warranty of
-MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
-GNU General Public License for more details.
-You should have received a copy of the GNU General Public License
-along with this program.  If not, see <http://www.gnu.org/licenses/>.
-*/
-
-package common
-
-type IRooms interface {
-	HasRoom(int, int) bool
-	HasRoomForTwo(int, int) bool
-	Enter(bool, int, int, IGameObject)
-	Leave(bool, int, int, IGameObject)
-	GetCreatures(int, int) []ICreature
-	GetHealths(int, int) []IHealth
-	GetStrengths(int, int) []IStrength
-	GetTreasures(int, int) []ITreasure
-	GetGameObjects(int, int) []IGameObject
-}
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package common
+
+type IRooms interface {
+	HasRoom(int, int) bool
+	HasRoomForTwo(int, int) bool
+	Enter(bool, int, int, IGameObject)
+	Leave(bool, int, int, IGameObject)
+	GetCreatures(int, int) []ICreature
+	GetHealths(int, int) []IHealth
+	GetStrengths(int, int) []IStrength
+	GetTreasures(int, int) []ITreasure
+	GetGameObjects(int, int) []IGameObject
+}
@@ -0,0 +1,185 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type SaveGame struct {
+	Version int
+	Saved   time.Time
+	Slot    string
+	Round   int
+	Player  string
+	State   *Message
+}
+
+var saveMigrations = map[int]func(map[string]interface{}) error{
+	1: migrateConfig,
+}
+
+func migrateConfig(raw map[string]interface{}) error {
+	state, _ := raw["State"].(map[string]interface{})
+	params, _ := state["Params"].(map[string]interface{})
+	s, ok := params["Config"].(string)
+
+	if !ok {
+		return errors.New("save has no config")
+	}
+
+	config := map[string]interface{}{}
+
+	if e := json.Unmarshal([]byte(s), &config); e != nil {
+		return errors.New("save has no valid config: " + e.Error())
+	}
+
+	defaults := map[string]interface{}{}
+	bs, _ := json.Marshal(NewConfig())
+	json.Unmarshal(bs, &defaults)
+
+	for key, value := range defaults {
+		if _, ok := config[key]; !ok {
+			config[key] = value
+		}
+	}
+
+	bs, _ = json.Marshal(config)
+	params["Config"] = string(bs)
+
+	return nil
+}
+
+func SavePath(slot string) string {
+	return filepath.Join(SAVE_DIR, slot+SAVE_EXT)
+}
+
+func WriteSave(s *SaveGame) error {
+	if !ValidName(s.Slot) {
+		return errors.New("invalid save slot " + s.Slot)
+	}
+
+	if e := os.MkdirAll(SAVE_DIR, 0755); e != nil {
+		return e
+	}
+
+	bs, e := json.Marshal(s)
+
+	if e != nil {
+		return e
+	}
+
+	path := SavePath(s.Slot)
+	tmp := path + ".tmp"
+
+	if e := ioutil.WriteFile(tmp, bs, 0644); e != nil {
+		return e
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func ReadSave(slot string) (*SaveGame, error) {
+	if !ValidName(slot) {
+		return nil, errors.New("invalid save slot " + slot)
+	}
+
+	bs, e := ioutil.ReadFile(SavePath(slot))
+
+	if e != nil {
+		return nil, e
+	}
+
+	raw := map[string]interface{}{}
+
+	if e := json.Unmarshal(bs, &raw); e != nil {
+		return nil, errors.New("save " + slot + " is corrupt: " + e.Error())
+	}
+
+	v, ok := raw["Version"].(float64)
+
+	if !ok || v < 1 {
+		return nil, errors.New("save " + slot + " has no version")
+	}
+
+	version := int(v)
+
+	if version > SAVE_VERSION {
+		return nil, fmt.Errorf("save %s is from a newer version (%d)", slot, version)
+	}
+
+	for version < SAVE_VERSION {
+		migrate, ok := saveMigrations[version]
+
+		if !ok {
+			return nil, fmt.Errorf("save %s can not be migrated from version %d", slot, version)
+		}
+
+		if e := migrate(raw); e != nil {
+			return nil, e
+		}
+
+		version++
+		raw["Version"] = version
+	}
+
+	bs, _ = json.Marshal(raw)
+	s := &SaveGame{}
+
+	if e := json.Unmarshal(bs, s); e != nil {
+		return nil, errors.New("save " + slot + " is corrupt: " + e.Error())
+	}
+
+	if s.State == nil {
+		return nil, errors.New("save " + slot + " has no state")
+	}
+
+	s.Slot = slot
+
+	return s, nil
+}
+
+func SaveSlots() []string {
+	files, e := ioutil.ReadDir(SAVE_DIR)
+
+	if e != nil {
+		return []string{}
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].ModTime().After(files[j].ModTime()) })
+
+	slots := []string{}
+
+	for _, f := range files {
+		name := f.Name()
+
+		if f.IsDir() || filepath.Ext(name) != SAVE_EXT {
+			continue
+		}
+
+		slots = append(slots, strings.TrimSuffix(name, SAVE_EXT))
+	}
+
+	return slots
+}
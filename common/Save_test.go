@@ -0,0 +1,158 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package common
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+const saveV1 = `{
+  "Version": 1,
+  "Saved": "2026-10-01T12:00:00Z",
+  "Slot": "old",
+  "Round": 2,
+  "Player": "p1",
+  "State": {
+    "Class": "Game",
+    "ID": "g1",
+    "Action": "Save",
+    "Params": {
+      "Session": "s1",
+      "Config": "{\"Name\":\"Ann\",\"Color\":523,\"WorldWidth\":60,\"WorldHeight\":30,\"Capacity\":5,\"Difficulty\":3}"
+    },
+    "MultiParams": {
+      "Players": ["{\"Class\":\"Player\",\"Name\":\"Ann\",\"ID\":\"p1\",\"X\":3,\"Y\":4}"]
+    }
+  }
+}`
+
+func inSaveDir(t *testing.T) {
+	wd, _ := os.Getwd()
+	dir, e := ioutil.TempDir("", "zahhak2")
+
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	os.Chdir(dir)
+	os.MkdirAll(SAVE_DIR, 0755)
+
+	t.Cleanup(func() {
+		os.Chdir(wd)
+		os.RemoveAll(dir)
+	})
+}
+
+func TestReadSaveMigratesVersion1(t *testing.T) {
+	inSaveDir(t)
+
+	if e := ioutil.WriteFile(SavePath("old"), []byte(saveV1), 0644); e != nil {
+		t.Fatal(e)
+	}
+
+	s, e := ReadSave("old")
+
+	if e != nil {
+		t.Fatalf("could not read version 1 save: %v", e)
+	}
+
+	if s.Version != SAVE_VERSION || s.Round != 2 || s.Player != "p1" {
+		t.Errorf("save read as version %d round %d player %s", s.Version, s.Round, s.Player)
+	}
+
+	config := &Config{}
+
+	if e := json.Unmarshal([]byte(s.State.Params["Config"]), config); e != nil {
+		t.Fatal(e)
+	}
+
+	if config.Name != "Ann" || config.WorldWidth != 60 || config.Difficulty != 3 {
+		t.Errorf("saved settings changed: %+v", config)
+	}
+
+	if config.FPS != FPS || config.Vision != VISION || config.Movement != MOVEMENT {
+		t.Errorf("missing settings not defaulted: fps %d vision %d movement %q", config.FPS, config.Vision, config.Movement)
+	}
+
+	if len(s.State.MultiParams["Players"]) != 1 {
+		t.Errorf("players lost in migration")
+	}
+}
+
+func TestReadSaveRejectsUnversioned(t *testing.T) {
+	inSaveDir(t)
+
+	if e := ioutil.WriteFile(SavePath("bare"), []byte(`{"State": {}}`), 0644); e != nil {
+		t.Fatal(e)
+	}
+
+	if _, e := ReadSave("bare"); e == nil {
+		t.Error("save without a version accepted")
+	}
+}
+
+func TestWriteSaveRoundTrip(t *testing.T) {
+	inSaveDir(t)
+
+	state := NewMessage("Game", "g1", "Save")
+	state.Params["Session"] = "s1"
+
+	if e := WriteSave(&SaveGame{Version: SAVE_VERSION, Slot: "one", Round: 3, Player: "p1", State: state}); e != nil {
+		t.Fatal(e)
+	}
+
+	s, e := ReadSave("one")
+
+	if e != nil {
+		t.Fatalf("could not read save: %v", e)
+	}
+
+	if s.Slot != "one" || s.Round != 3 || s.Player != "p1" || s.State.Params["Session"] != "s1" {
+		t.Errorf("save read back as %+v", s)
+	}
+
+	if e := WriteSave(&SaveGame{Version: SAVE_VERSION, Slot: "../one", State: state}); e == nil {
+		t.Error("invalid slot name accepted")
+	}
+}
+
+func TestReadSaveRejectsNewerVersion(t *testing.T) {
+	inSaveDir(t)
+
+	if e := ioutil.WriteFile(SavePath("new"), []byte(`{"Version": 1000, "State": {}}`), 0644); e != nil {
+		t.Fatal(e)
+	}
+
+	if _, e := ReadSave("new"); e == nil {
+		t.Error("save from a newer version accepted")
+	}
+}
+
+func TestSaveSlots(t *testing.T) {
+	inSaveDir(t)
+
+	ioutil.WriteFile(SavePath("a"), []byte(`{}`), 0644)
+	ioutil.WriteFile(SAVE_DIR+"/notes.txt", []byte(``), 0644)
+
+	slots := SaveSlots()
+
+	if len(slots) != 1 || slots[0] != "a" {
+		t.Errorf("slots listed as %v", slots)
+	}
+}
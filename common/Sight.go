@@ -0,0 +1,79 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package common
+
+func Visible(fromX, fromY, x, y, radius, width, height int, blocked func(int, int) bool) bool {
+	dx, dy := Offset(fromX, x, width), Offset(fromY, y, height)
+
+	if dx*dx+dy*dy > radius*radius {
+		return false
+	}
+
+	if blocked == nil {
+		return true
+	}
+
+	return LineOfSight(fromX, fromY, dx, dy, width, height, blocked)
+}
+
+func Offset(from, to, size int) int {
+	d := to - from
+
+	if d > size/2 {
+		d -= size
+	} else if d < -size/2 {
+		d += size
+	}
+
+	return d
+}
+
+func LineOfSight(fromX, fromY, dx, dy, width, height int, blocked func(int, int) bool) bool {
+	stepX, stepY := 1, 1
+
+	if dx < 0 {
+		stepX, dx = -1, -dx
+	}
+
+	if dy < 0 {
+		stepY, dy = -1, -dy
+	}
+
+	x, y := 0, 0
+	e := dx - dy
+
+	for {
+		e2 := 2 * e
+
+		if e2 > -dy {
+			e -= dy
+			x += stepX
+		}
+
+		if e2 < dx {
+			e += dx
+			y += stepY
+		}
+
+		if x == dx*stepX && y == dy*stepY {
+			return true
+		}
+
+		if blocked((fromX+x+width)%width, (fromY+y+height)%height) {
+			return false
+		}
+	}
+}
@@ -1,45 +1,45 @@
-/*
-Zahhak2, a Golang console game.
-Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
-This program is free software: you can redistribute it and/or modify
-it under the terms of the GNU General Public License as published by
-the Free Software Foundation, either version 3 of the License, or
-(at your option) any later version.
-This program is distributed in the hope that it will be useful,
-but WITHOUT ANY WARRANTY; without even the implied warranty of
-MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
-GNU General Public License for more details.
-You should have received a copy of the GNU General Public License
-along with this program.  If not, see <http://www.gnu.org/licenses/>.
-*/
-
-package gameobjects
-
-import (
-	z "../common"
-)
-
-type Item struct {
-	*GameObject
-	Points int
-}
-
-func NewItem(broadcast chan *z.Message) *Item {
-	return &Item{
-		GameObject: &GameObject{
-			Class:     "Item",
-			Name:      "Item",
-			Symbol:    'I',
-			broadcast: broadcast,
-			Paused:    true,
-		},
-		Points: 10,
-	}
-}
-
-func (i *Item) GetPoints() int {
-	i.RLock()
-	defer i.RUnlock()
-
-	return i.Points
-}
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package gameobjects
+
+import (
+	z "../common"
+)
+
+type Item struct {
+	*GameObject
+	Points int
+}
+
+func NewItem(broadcast chan *z.Message) *Item {
+	return &Item{
+		GameObject: &GameObject{
+			Class:     "Item",
+			Name:      "Item",
+			Symbol:    'I',
+			broadcast: broadcast,
+			Paused:    true,
+		},
+		Points: 10,
+	}
+}
+
+func (i *Item) GetPoints() int {
+	i.RLock()
+	defer i.RUnlock()
+
+	return i.Points
+}
@@ -1,289 +1,686 @@
-/*
-Zahhak2, a Golang console game.
-Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
-This program is free software: you can redistribute it and/or modify
-it under the terms of the GNU General Public License as published by
-the Free Software Foundation, either version 3 of the License, or
-(at your option) any later version.
-This program is distributed in the hope that it will be useful,
-but WITHOUT ANY WARRANTY; without even the implied warranty of
-MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
-GNU General Public License for more details.
-You should have received a copy of the GNU General Public License
-along with this program.  If not, see <http://www.gnu.org/licenses/>.
-*/
-
-package menu
-
-import (
-	"fmt"
-	"os"
-	"strconv"
-	"strings"
-
-	gv "github.com/asaskevich/govalidator"
-	tb "github.com/nsf/termbox-go"
-
-	z "../common"
-	zn "../networking"
-)
-
-type Menu struct {
-	g *Graphics
-
-	Quit        bool
-	Multiplayer bool
-	Server      bool
-	Host        string
-	Port        string
-	Name        string
-}
-
-func NewMenu() *Menu {
-	return &Menu{}
-}
-
-func (m *Menu) Options() {
-	m.Host = z.HOST_IP
-	m.Port = z.PORT_NUM
-	m.Name = z.NAME
-
-	m.main()
-
-	if m.Quit || !m.Multiplayer {
-		return
-	}
-
-	m.multiplayer()
-
-	if m.Quit {
-		return
-	}
-
-	if m.Server {
-		m.host()
-
-		return
-	}
-
-	m.client()
-}
-
-func (m *Menu) background() {
-	m.g = NewGraphics(3.0)
-
-	m.g.BlankScreen()
-
-	m.g.DrawBackground("*", z.BoldColorWhite, 2.5)
-
-	m.g.DrawBackground("*", z.ColorWhite, 3)
-
-	m.g.DrawBackground("*", z.ColorBlack, 4)
-}
-
-func (m *Menu) main() {
-	m.g.BlankScreen()
-	m.background()
-	m.g.Resize(10)
-
-	cX, cY := m.g.GetCenter()
-	uX, _ := m.g.GetUnits()
-
-	m.g.Print(cX-2*uX, cY-6, "1. Single player", z.BoldColorYellow)
-	m.g.Print(cX-2*uX, cY-4, "2. Multi-player", z.BoldColorYellow)
-	m.g.Print(cX-2*uX, cY-2, "3. Quit", z.BoldColorYellow)
-	m.g.Print(cX-2*uX, cY+3, "Enter 1-3 (default 1):", z.BoldColorCyan)
-
-	m.g.SetCursor(cX-2*uX+len("Enter 1-3 (default 1):"), cY+3)
-
-	m.g.Flush()
-
-	c, _ := m.g.ReadChar()
-	s := fmt.Sprintf("%c", c)
-	n, _ := strconv.Atoi(s)
-
-	switch n {
-	case 2:
-		m.Multiplayer = true
-	case 3:
-		m.Quit = true
-	}
-
-	tb.HideCursor()
-
-}
-
-func (m *Menu) multiplayer() {
-	m.g.BlankScreen()
-	m.background()
-	m.g.Resize(10)
-
-	cX, cY := m.g.GetCenter()
-	uX, _ := m.g.GetUnits()
-
-	m.g.Print(cX-2*uX, cY-6, "1. Host game (server)", z.BoldColorYellow)
-	m.g.Print(cX-2*uX, cY-4, "2. Connect to a game (client)", z.BoldColorYellow)
-	m.g.Print(cX-2*uX, cY-2, "3. Quit", z.BoldColorYellow)
-	m.g.Print(cX-2*uX, cY+3, "Enter 1-3 (default 1):", z.BoldColorCyan)
-
-	m.g.SetCursor(cX-2*uX+len("Enter 1-3 (default 1):"), cY+3)
-
-	m.g.Flush()
-
-	c, _ := m.g.ReadChar()
-	n, _ := strconv.Atoi(string(c))
-
-	switch n {
-	case 2:
-		m.Server = false
-	case 3:
-		m.Quit = true
-	default:
-		m.Server = true
-	}
-
-	tb.HideCursor()
-}
-
-func (m *Menu) host() {
-	m.g.BlankScreen()
-	m.background()
-	m.g.Resize(10)
-
-	n, e := strconv.Atoi(m.Port)
-
-	if e != nil || !zn.IsTCPPortAvailable(n) {
-		tb.Close()
-
-		println("Error: server's port " + m.Port + " can not be opened.")
-		os.Exit(1)
-	}
-
-	cX, cY := m.g.GetCenter()
-	uX, _ := m.g.GetUnits()
-
-	remoteHostnames, e := zn.RemoteHostnames()
-
-	if e == nil {
-		m.g.Print(cX-2*uX, cY-6, "Internet hostname", z.BoldColorYellow|z.AttrUnderline)
-		m.g.Print(cX-2*uX, cY-5, remoteHostnames[0], z.BoldColorYellow)
-	} else {
-		m.g.Print(cX-2*uX, cY-6, "Internet hostname", z.BoldColorYellow|z.AttrUnderline)
-		m.g.Print(cX-2*uX, cY-5, "DNS unable to resolve", z.BoldColorYellow)
-	}
-
-	remoteIP, e := zn.RemoteIP()
-
-	if e == nil {
-		m.g.Print(cX-2*uX, cY-4, "Internet IP", z.BoldColorYellow|z.AttrUnderline)
-		m.g.Print(cX-2*uX, cY-3, remoteIP, z.BoldColorYellow)
-	} else {
-		m.g.Print(cX-2*uX, cY-4, "Internet IP", z.BoldColorYellow|z.AttrUnderline)
-		m.g.Print(cX-2*uX, cY-3, e.Error(), z.BoldColorYellow)
-	}
-
-	localHostnames, e := zn.LocalHostnames()
-
-	if e == nil {
-		m.g.Print(cX-2*uX, cY-2, "Local hostname", z.BoldColorYellow|z.AttrUnderline)
-
-		if len(localHostnames) > 1 {
-			m.g.Print(cX-2*uX, cY-1, localHostnames[0], z.BoldColorYellow)
-		} else {
-			m.g.Print(cX-2*uX, cY-1, "DNS unable to resolve", z.BoldColorYellow)
-		}
-	} else {
-		m.g.Print(cX-2*uX, cY-2, "Local hostname", z.BoldColorYellow|z.AttrUnderline)
-		m.g.Print(cX-2*uX, cY-1, e.Error(), z.BoldColorYellow)
-	}
-
-	localIP, e := zn.LocalIP()
-
-	if e == nil {
-		m.g.Print(cX-2*uX, cY, "Local IP", z.BoldColorYellow|z.AttrUnderline)
-		m.g.Print(cX-2*uX, cY+1, localIP, z.BoldColorYellow)
-
-		m.Host = localIP
-	} else {
-		m.g.Print(cX-2*uX, cY, "Local IP", z.BoldColorYellow|z.AttrUnderline)
-		m.g.Print(cX-2*uX, cY+1, e.Error(), z.BoldColorYellow)
-	}
-
-	m.g.Print(cX-2*uX, cY+3, "Press any key to start:", z.BoldColorCyan)
-
-	m.g.SetCursor(cX-2*uX+len("Press any key to start:"), cY+3)
-
-	m.g.Flush()
-
-	m.g.ReadChar()
-
-	m.Name = "Host"
-
-	tb.HideCursor()
-}
-
-func (m *Menu) client() {
-	m.g.BlankScreen()
-	m.background()
-	m.g.Resize(10)
-
-	cX, cY := m.g.GetCenter()
-	uX, _ := m.g.GetUnits()
-
-	m.g.Print(cX-2*uX, cY-6, "Enter server's address", z.BoldColorYellow|z.AttrUnderline)
-	m.g.Print(cX-2*uX, cY-4, "Format: address [optional port]", z.BoldColorYellow)
-	m.g.Print(cX-2*uX, cY-3, "e.x. 192.168.0.100 1947", z.BoldColorYellow)
-	m.g.Print(cX-2*uX, cY-1, "Press enter when done:", z.BoldColorCyan)
-
-	x := cX - 2*uX
-
-	s := m.g.Readline(x, cY)
-	i := strings.Index(s, " ")
-
-	if i > 0 {
-		m.Host = s[:i]
-		s := s[i+1:]
-		n, e := strconv.Atoi(s)
-
-		if e == nil && n > 0 && n < 65535 {
-			m.Port = s
-		}
-	} else {
-		m.Host = s
-	}
-
-	m.g.Print(cX-2*uX, cY+2, "Type your name:", z.BoldColorCyan)
-
-	m.Name = strings.TrimSpace(m.g.Readline(x, cY+3))
-
-	m.test()
-
-	tb.HideCursor()
-}
-
-func (m *Menu) test() {
-	if m.Name == "" {
-		m.Name = "Opponent"
-	}
-
-	if m.Host == "" {
-		tb.Close()
-
-		println("Error: server's address is blank")
-		os.Exit(1)
-	}
-
-	if !gv.IsHost(m.Host) {
-		tb.Close()
-
-		println("Error: server's address " + m.Host + " is not valid")
-		os.Exit(1)
-	}
-
-	if !zn.IsRemotePortOpen(m.Host, m.Port) {
-		tb.Close()
-
-		println("Error: server's address " + m.Host + " and port " + m.Port + " is not accessible")
-		os.Exit(1)
-	}
-}
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package menu
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	gv "github.com/asaskevich/govalidator"
+	tb "github.com/nsf/termbox-go"
+
+	z "../common"
+	zn "../networking"
+)
+
+type Menu struct {
+	g *Graphics
+
+	Quit        bool
+	Multiplayer bool
+	Server      bool
+	Host        string
+	Bind        string
+	Port        string
+	Name        string
+	Color       tb.Attribute
+	Spectator   bool
+	Browse      bool
+	Match       string
+	Password    string
+	Token       string
+	TLS         bool
+	Fingerprint string
+	Load        string
+}
+
+func NewMenu() *Menu {
+	return &Menu{}
+}
+
+func (m *Menu) Options() {
+	m.Host = z.HOST_IP
+	m.Bind = z.BIND_ADDR
+	m.Port = z.PORT_NUM
+	m.Name = z.NAME
+	m.Color = z.BoldColorYellow
+
+	m.main()
+
+	if m.Quit {
+		return
+	}
+
+	if m.Load != "" {
+		m.load()
+
+		return
+	}
+
+	if !m.Multiplayer {
+		return
+	}
+
+	m.multiplayer()
+
+	if m.Quit {
+		return
+	}
+
+	if m.Server {
+		m.host()
+
+		return
+	}
+
+	if m.Browse && m.browse() {
+		m.identity()
+
+		return
+	}
+
+	m.client()
+}
+
+func (m *Menu) FindGame() bool {
+	m.background()
+
+	return m.browse()
+}
+
+func (m *Menu) background() {
+	m.g = NewGraphics(3.0)
+
+	m.g.BlankScreen()
+
+	m.g.DrawBackground("*", z.BoldColorWhite, 2.5)
+
+	m.g.DrawBackground("*", z.ColorWhite, 3)
+
+	m.g.DrawBackground("*", z.ColorBlack, 4)
+}
+
+func (m *Menu) main() {
+	m.g.BlankScreen()
+	m.background()
+	m.g.Resize(10)
+
+	cX, cY := m.g.GetCenter()
+	uX, _ := m.g.GetUnits()
+
+	m.g.Print(cX-2*uX, cY-6, "1. Single player", z.BoldColorYellow)
+	m.g.Print(cX-2*uX, cY-4, "2. Load game", z.BoldColorYellow)
+	m.g.Print(cX-2*uX, cY-2, "3. Multi-player", z.BoldColorYellow)
+	m.g.Print(cX-2*uX, cY, "4. Quit", z.BoldColorYellow)
+	m.g.Print(cX-2*uX, cY+3, "Enter 1-4 (default 1):", z.BoldColorCyan)
+
+	m.g.SetCursor(cX-2*uX+len("Enter 1-4 (default 1):"), cY+3)
+
+	m.g.Flush()
+
+	c, _ := m.g.ReadChar()
+	s := fmt.Sprintf("%c", c)
+	n, _ := strconv.Atoi(s)
+
+	switch n {
+	case 2:
+		m.Load = z.AUTOSAVE_SLOT
+	case 3:
+		m.Multiplayer = true
+	case 4:
+		m.Quit = true
+	}
+
+	tb.HideCursor()
+
+}
+
+func (m *Menu) load() {
+	m.g.BlankScreen()
+	m.background()
+	m.g.Resize(10)
+
+	cX, cY := m.g.GetCenter()
+	uX, _ := m.g.GetUnits()
+	x := cX - 2*uX
+
+	m.g.Print(x, cY-8, "Saved games", z.BoldColorYellow|z.AttrUnderline)
+
+	slots := z.SaveSlots()
+
+	if len(slots) > 9 {
+		slots = slots[:9]
+	}
+
+	if len(slots) == 0 {
+		m.g.Print(x, cY-6, "No saved games", z.BoldColorYellow)
+	}
+
+	for i, slot := range slots {
+		s := fmt.Sprintf("%d. %s", i+1, slot)
+		m.g.Print(x, cY-6+i, s, z.BoldColorYellow)
+	}
+
+	m.g.Print(x, cY+4, "Type 1-9, a slot name, or enter for 1:", z.BoldColorCyan)
+
+	s := strings.TrimSpace(m.g.Readline(x, cY+5))
+	n, e := strconv.Atoi(s)
+
+	switch {
+	case s == "" && len(slots) > 0:
+		m.Load = slots[0]
+
+	case e == nil && n >= 1 && n <= len(slots):
+		m.Load = slots[n-1]
+
+	case s != "":
+		m.Load = s
+	}
+
+	tb.HideCursor()
+}
+
+func (m *Menu) multiplayer() {
+	m.g.BlankScreen()
+	m.background()
+	m.g.Resize(10)
+
+	cX, cY := m.g.GetCenter()
+	uX, _ := m.g.GetUnits()
+
+	m.g.Print(cX-2*uX, cY-6, "1. Host game (server)", z.BoldColorYellow)
+	m.g.Print(cX-2*uX, cY-5, "2. Connect to a game (client)", z.BoldColorYellow)
+	m.g.Print(cX-2*uX, cY-4, "3. Find a game on the LAN", z.BoldColorYellow)
+	m.g.Print(cX-2*uX, cY-3, "4. Watch a game (spectator)", z.BoldColorYellow)
+	m.g.Print(cX-2*uX, cY-2, "5. Quit", z.BoldColorYellow)
+	m.g.Print(cX-2*uX, cY+3, "Enter 1-5 (default 1):", z.BoldColorCyan)
+
+	m.g.SetCursor(cX-2*uX+len("Enter 1-5 (default 1):"), cY+3)
+
+	m.g.Flush()
+
+	c, _ := m.g.ReadChar()
+	n, _ := strconv.Atoi(string(c))
+
+	switch n {
+	case 2:
+		m.Server = false
+	case 3:
+		m.Server = false
+		m.Browse = true
+	case 4:
+		m.Server = false
+		m.Spectator = true
+	case 5:
+		m.Quit = true
+	default:
+		m.Server = true
+	}
+
+	tb.HideCursor()
+}
+
+func (m *Menu) host() {
+	m.g.BlankScreen()
+	m.background()
+	m.g.Resize(10)
+
+	n, e := strconv.Atoi(m.Port)
+
+	if e != nil || !zn.IsTCPPortAvailable(m.Bind, n) {
+		tb.Close()
+
+		println("Error: server's port " + m.Port + " can not be opened.")
+		os.Exit(1)
+	}
+
+	cX, cY := m.g.GetCenter()
+	uX, _ := m.g.GetUnits()
+
+	remoteHostnames, e := zn.RemoteHostnames()
+
+	if e == nil {
+		m.g.Print(cX-2*uX, cY-6, "Internet hostname", z.BoldColorYellow|z.AttrUnderline)
+		m.g.Print(cX-2*uX, cY-5, remoteHostnames[0], z.BoldColorYellow)
+	} else {
+		m.g.Print(cX-2*uX, cY-6, "Internet hostname", z.BoldColorYellow|z.AttrUnderline)
+		m.g.Print(cX-2*uX, cY-5, "DNS unable to resolve", z.BoldColorYellow)
+	}
+
+	remoteIP, e := zn.RemoteIP()
+
+	if e == nil {
+		m.g.Print(cX-2*uX, cY-4, "Internet IP", z.BoldColorYellow|z.AttrUnderline)
+		m.g.Print(cX-2*uX, cY-3, remoteIP, z.BoldColorYellow)
+	} else {
+		m.g.Print(cX-2*uX, cY-4, "Internet IP", z.BoldColorYellow|z.AttrUnderline)
+		m.g.Print(cX-2*uX, cY-3, e.Error(), z.BoldColorYellow)
+	}
+
+	localHostnames, e := zn.LocalHostnames()
+
+	if e == nil {
+		m.g.Print(cX-2*uX, cY-2, "Local hostname", z.BoldColorYellow|z.AttrUnderline)
+
+		if len(localHostnames) > 1 {
+			m.g.Print(cX-2*uX, cY-1, localHostnames[0], z.BoldColorYellow)
+		} else {
+			m.g.Print(cX-2*uX, cY-1, "DNS unable to resolve", z.BoldColorYellow)
+		}
+	} else {
+		m.g.Print(cX-2*uX, cY-2, "Local hostname", z.BoldColorYellow|z.AttrUnderline)
+		m.g.Print(cX-2*uX, cY-1, e.Error(), z.BoldColorYellow)
+	}
+
+	localIP, e := zn.LocalIP()
+
+	if e == nil {
+		m.g.Print(cX-2*uX, cY, "Local IP", z.BoldColorYellow|z.AttrUnderline)
+		m.g.Print(cX-2*uX, cY+1, localIP, z.BoldColorYellow)
+
+		m.Host = localIP
+	} else {
+		m.g.Print(cX-2*uX, cY, "Local IP", z.BoldColorYellow|z.AttrUnderline)
+		m.g.Print(cX-2*uX, cY+1, e.Error(), z.BoldColorYellow)
+	}
+
+	m.g.Print(cX-2*uX, cY+3, "Press any key to start:", z.BoldColorCyan)
+
+	m.g.SetCursor(cX-2*uX+len("Press any key to start:"), cY+3)
+
+	m.g.Flush()
+
+	m.g.ReadChar()
+
+	m.Name = "Host"
+
+	tb.HideCursor()
+}
+
+func (m *Menu) client() {
+	m.g.BlankScreen()
+	m.background()
+	m.g.Resize(10)
+
+	cX, cY := m.g.GetCenter()
+	uX, _ := m.g.GetUnits()
+
+	m.g.Print(cX-2*uX, cY-6, "Enter server's address", z.BoldColorYellow|z.AttrUnderline)
+	m.g.Print(cX-2*uX, cY-4, "Format: address [optional port]", z.BoldColorYellow)
+	m.g.Print(cX-2*uX, cY-3, "e.x. 192.168.0.100 1947 or ::1 1947", z.BoldColorYellow)
+	m.g.Print(cX-2*uX, cY-1, "Press enter when done:", z.BoldColorCyan)
+
+	x := cX - 2*uX
+
+	s := strings.TrimSpace(m.g.Readline(x, cY))
+	i := strings.Index(s, " ")
+
+	if i > 0 {
+		m.Host = strings.Trim(s[:i], "[]")
+		s := s[i+1:]
+		n, e := strconv.Atoi(s)
+
+		if e == nil && n > 0 && n < 65535 {
+			m.Port = s
+		}
+	} else {
+		m.Host = strings.Trim(s, "[]")
+	}
+
+	m.g.Print(x, cY+2, "Use TLS (y/N):", z.BoldColorCyan)
+	m.g.SetCursor(x+len("Use TLS (y/N):"), cY+2)
+	m.g.Flush()
+
+	c, _ := m.g.ReadChar()
+	m.TLS = c == 'y' || c == 'Y'
+
+	if m.TLS {
+		m.g.Print(x, cY+4, "Pin fingerprint (optional):", z.BoldColorCyan)
+		m.Fingerprint = strings.TrimSpace(m.g.Readline(x, cY+5))
+	}
+
+	m.match()
+
+	m.identity()
+}
+
+func (m *Menu) match() {
+	matches, e := zn.ListMatches(m.Host, m.Port, zn.Security{TLS: m.TLS, Fingerprint: m.Fingerprint})
+
+	if e != nil || len(matches) == 0 {
+		return
+	}
+
+	m.g.BlankScreen()
+	m.background()
+	m.g.Resize(10)
+
+	cX, cY := m.g.GetCenter()
+	uX, _ := m.g.GetUnits()
+	x := cX - 2*uX
+
+	m.g.Print(x, cY-8, "Matches on "+m.Host+" "+m.Port, z.BoldColorYellow|z.AttrUnderline)
+
+	if len(matches) > 9 {
+		matches = matches[:9]
+	}
+
+	for i, match := range matches {
+		s := fmt.Sprintf("%d. %-16s %d players", i+1, match.Name, match.Players)
+		m.g.Print(x, cY-6+i, s, z.BoldColorYellow)
+	}
+
+	m.g.Print(x, cY+4, "Type 1-9, a new match name, or enter for 1:", z.BoldColorCyan)
+
+	s := strings.TrimSpace(m.g.Readline(x, cY+5))
+	n, e := strconv.Atoi(s)
+
+	switch {
+	case s == "":
+		m.Match = matches[0].Name
+
+	case e == nil && n >= 1 && n <= len(matches):
+		m.Match = matches[n-1].Name
+
+	default:
+		m.Match = s
+	}
+
+	tb.HideCursor()
+}
+
+func (m *Menu) identity() {
+	m.g.BlankScreen()
+	m.background()
+	m.g.Resize(10)
+
+	cX, cY := m.g.GetCenter()
+	uX, _ := m.g.GetUnits()
+
+	s := "Joining " + m.Host + " " + m.Port
+
+	if m.Match != "" {
+		s += " " + m.Match
+	}
+
+	m.g.Print(cX-2*uX, cY-6, s, z.BoldColorYellow|z.AttrUnderline)
+
+	if m.Fingerprint != "" {
+		m.g.Print(cX-2*uX, cY-5, "TLS "+m.Fingerprint, z.BoldColorGreen)
+	}
+
+	m.name(cX-2*uX, cY-4)
+}
+
+func (m *Menu) name(x, y int) {
+	m.g.Print(x, y, "Type your name:", z.BoldColorCyan)
+
+	m.Name = strings.TrimSpace(m.g.Readline(x, y+1))
+
+	m.colour(x, y+3)
+
+	m.g.Print(x, y+6, "Password (optional):", z.BoldColorCyan)
+	m.Password = strings.TrimSpace(m.g.Readline(x, y+7))
+
+	m.g.Print(x, y+9, "Token (optional):", z.BoldColorCyan)
+	m.Token = strings.TrimSpace(m.g.Readline(x, y+10))
+
+	m.test()
+
+	tb.HideCursor()
+}
+
+func (m *Menu) browse() bool {
+	browser := zn.NewBrowser(zn.DISCOVERY_TARGETS)
+
+	if e := browser.Run(); e != nil {
+		m.g.BlankScreen()
+		m.background()
+		m.g.Resize(10)
+
+		cX, cY := m.g.GetCenter()
+		uX, _ := m.g.GetUnits()
+
+		m.g.Print(cX-2*uX, cY-6, "LAN games", z.BoldColorYellow|z.AttrUnderline)
+		m.g.Print(cX-2*uX, cY-4, e.Error(), z.BoldColorRed)
+		m.g.Print(cX-2*uX, cY+3, "Press any key to enter an address:", z.BoldColorCyan)
+		m.g.Flush()
+
+		m.g.ReadChar()
+
+		return false
+	}
+
+	defer browser.Close()
+
+	events := make(chan tb.Event)
+
+	go func() {
+		for {
+			ev := tb.PollEvent()
+
+			if ev.Type == tb.EventInterrupt {
+				close(events)
+
+				return
+			}
+
+			events <- ev
+		}
+	}()
+
+	defer func() {
+		tb.Interrupt()
+
+		for range events {
+		}
+	}()
+
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		games := browser.Games()
+
+		if len(games) > 9 {
+			games = games[:9]
+		}
+
+		m.games(games)
+
+		select {
+		case ev := <-events:
+			if ev.Type != tb.EventKey {
+				continue
+			}
+
+			if ev.Key == tb.KeyEsc || ev.Key == tb.KeyEnter {
+				return false
+			}
+
+			n, e := strconv.Atoi(string(ev.Ch))
+
+			if e != nil || n < 1 || n > len(games) || !games[n-1].Compatible() {
+				continue
+			}
+
+			if games[n-1].TLS && !m.trust(games[n-1], events) {
+				continue
+			}
+
+			m.Host = games[n-1].Address
+			m.Port = games[n-1].Port
+			m.Match = games[n-1].Match
+			m.TLS = games[n-1].TLS
+
+			if m.TLS {
+				m.Fingerprint = games[n-1].Fingerprint
+			}
+
+			return true
+
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Menu) trust(game *zn.Beacon, events chan tb.Event) bool {
+	if m.Fingerprint != "" && zn.SameFingerprint(m.Fingerprint, game.Fingerprint) {
+		return true
+	}
+
+	m.g.BlankScreen()
+	m.background()
+	m.g.Resize(10)
+
+	cX, cY := m.g.GetCenter()
+	uX, _ := m.g.GetUnits()
+	x := cX - 2*uX
+
+	m.g.Print(x, cY-8, "TLS fingerprint of "+game.Name+" "+game.Key(), z.BoldColorYellow|z.AttrUnderline)
+	m.g.Print(x, cY-6, game.Fingerprint, z.BoldColorGreen)
+
+	if m.Fingerprint != "" {
+		m.g.Print(x, cY-4, "Does not match -fingerprint "+m.Fingerprint, z.BoldColorRed)
+		m.g.Print(x, cY-2, "Press any key to go back", z.BoldColorCyan)
+		m.g.Flush()
+
+		m.key(events)
+
+		return false
+	}
+
+	m.g.Print(x, cY-4, "Anyone on the LAN can announce a game.", z.BoldColorWhite)
+	m.g.Print(x, cY-3, "Compare it with the fingerprint the host shows.", z.BoldColorWhite)
+	m.g.Print(x, cY-1, "Y: Trust and join / other keys: Back", z.BoldColorCyan)
+	m.g.Flush()
+
+	ev, ok := m.key(events)
+
+	return ok && (ev.Ch == 'y' || ev.Ch == 'Y')
+}
+
+func (m *Menu) key(events chan tb.Event) (tb.Event, bool) {
+	for ev := range events {
+		if ev.Type == tb.EventKey {
+			return ev, true
+		}
+	}
+
+	return tb.Event{}, false
+}
+
+func (m *Menu) games(games []*zn.Beacon) {
+	m.g.BlankScreen()
+	m.background()
+	m.g.Resize(10)
+
+	cX, cY := m.g.GetCenter()
+	uX, _ := m.g.GetUnits()
+	x := cX - 2*uX
+
+	m.g.Print(x, cY-8, "LAN games", z.BoldColorYellow|z.AttrUnderline)
+
+	if len(games) == 0 {
+		m.g.Print(x, cY-6, "Searching...", z.BoldColorYellow)
+	}
+
+	for i, g := range games {
+		s := fmt.Sprintf("%d. %-16s %-16s %s %dx%d %d players %s", i+1, g.Name, g.Match, g.Key(), g.WorldWidth, g.WorldHeight, g.Players, g.Phase)
+
+		if g.TLS {
+			s += " TLS"
+		}
+		color := z.BoldColorYellow
+
+		if !g.Compatible() {
+			s = fmt.Sprintf("%d. %-16s %s incompatible version", i+1, g.Name, g.Key())
+			color = z.ColorWhite
+		}
+
+		m.g.Print(x, cY-6+i, s, color)
+	}
+
+	m.g.Print(x, cY+4, "Press 1-9 to join, enter to type an address:", z.BoldColorCyan)
+
+	tb.HideCursor()
+	m.g.Flush()
+}
+
+func (m *Menu) colour(x, y int) {
+	colors := []tb.Attribute{
+		z.BoldColorMagenta,
+		z.BoldColorCyan,
+		z.BoldColorGreen,
+		z.BoldColorBlue,
+		z.BoldColorRed,
+		z.BoldColorWhite}
+
+	m.g.Print(x, y, "Pick your colour (default 1):", z.BoldColorCyan)
+
+	for i, c := range colors {
+		m.g.Print(x+i*3, y+1, fmt.Sprintf("%d.☺", i+1), c)
+	}
+
+	m.g.SetCursor(x+len("Pick your colour (default 1):"), y)
+	m.g.Flush()
+
+	c, _ := m.g.ReadChar()
+	n, e := strconv.Atoi(string(c))
+
+	if e != nil || n < 1 || n > len(colors) {
+		n = 1
+	}
+
+	m.Color = colors[n-1]
+}
+
+func (m *Menu) test() {
+	if m.Name == "" {
+		m.Name = "Opponent"
+	}
+
+	if !z.ValidName(m.Name) {
+		tb.Close()
+
+		println(fmt.Sprintf("Error: names are 1-%d letters, digits, - _ or .", z.NAME_LEN))
+		os.Exit(1)
+	}
+
+	if m.Host == "" {
+		tb.Close()
+
+		println("Error: server's address is blank")
+		os.Exit(1)
+	}
+
+	if !gv.IsHost(m.Host) {
+		tb.Close()
+
+		println("Error: server's address " + m.Host + " is not valid")
+		os.Exit(1)
+	}
+
+	if !zn.IsRemotePortOpen(m.Host, m.Port) {
+		tb.Close()
+
+		println("Error: server's address " + m.Host + " and port " + m.Port + " is not accessible")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package networking
+
+import (
+	"log"
+	"net"
+	"sort"
+	"strings"
+
+	z "../common"
+)
+
+type Credentials struct {
+	Name     string
+	Password string
+	Token    string
+}
+
+type Rejection struct {
+	Code   string
+	Reason string
+}
+
+func NewRejection(code, reason string) *Rejection {
+	return &Rejection{Code: code, Reason: reason}
+}
+
+func (r *Rejection) Error() string {
+	return r.Reason
+}
+
+func (r *Rejection) Message() *z.Message {
+	m := z.NewMessage("Server", "0", "Rejected")
+	m.Params["Code"] = r.Code
+	m.Params["Reason"] = r.Reason
+
+	return m
+}
+
+func (s *Server) Ban(target string) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.bans[strings.ToLower(target)] = true
+
+	log.Println("Server: banned " + target)
+}
+
+func (s *Server) Unban(target string) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	key := strings.ToLower(target)
+
+	if !s.bans[key] {
+		return false
+	}
+
+	delete(s.bans, key)
+
+	log.Println("Server: unbanned " + target)
+
+	return true
+}
+
+func (s *Server) Banned(name, host string) bool {
+	s.RLock()
+	defer s.RUnlock()
+
+	return (name != "" && s.bans[strings.ToLower(name)]) || (host != "" && s.bans[host])
+}
+
+func (s *Server) Bans() []string {
+	s.RLock()
+	defer s.RUnlock()
+
+	bans := make([]string, 0, len(s.bans))
+
+	for b := range s.bans {
+		bans = append(bans, b)
+	}
+
+	sort.Strings(bans)
+
+	return bans
+}
+
+func (s *Server) Kick(target string) int {
+	s.RLock()
+	matches := make([]*Match, 0, len(s.matches))
+
+	for _, m := range s.matches {
+		matches = append(matches, m)
+	}
+	s.RUnlock()
+
+	host := ""
+
+	if net.ParseIP(target) != nil {
+		host, target = target, ""
+	}
+
+	n := 0
+
+	for _, m := range matches {
+		for _, c := range m.hub.Find(target, host) {
+			log.Println("Server: kicking " + c.name + " " + c.address + " from " + m.name)
+
+			m.disconnect(c, NewRejection(z.REJECT_KICKED, "you were kicked from the server"))
+
+			if c.name != "" {
+				m.eventManager.Fire("Kick", true, c.name)
+			}
+
+			n++
+		}
+	}
+
+	return n
+}
+
+func remoteHost(address string) string {
+	host, _, e := net.SplitHostPort(address)
+
+	if e != nil {
+		return address
+	}
+
+	return host
+}
@@ -1,65 +1,178 @@
-/*
-Zahhak2, a Golang console game.
-Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
-This program is free software: you can redistribute it and/or modify
-it under the terms of the GNU General Public License as published by
-the Free Software Foundation, either version 3 of the License, or
-(at your option) any later version.
-This program is distributed in the hope that it will be useful,
-but WITHOUT ANY WARRANTY; without even the implied warranty of
-MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
-GNU General Public License for more details.
-You should have received a copy of the GNU General Public License
-along with this program.  If not, see <http://www.gnu.org/licenses/>.
-*/
-
-package networking
-
-type BroadcastHub struct {
-	connections map[*Connection]bool
-
-	register   chan *Connection
-	unregister chan *Connection
-
-	incoming chan []byte
-	outgoing chan []byte
-}
-
-func NewBroadcastHub(incoming chan []byte, outgoing chan []byte) *BroadcastHub {
-	return &BroadcastHub{
-		incoming:    incoming,
-		outgoing:    outgoing,
-		register:    make(chan *Connection, 1024),
-		unregister:  make(chan *Connection, 1024),
-		connections: make(map[*Connection]bool),
-	}
-}
-
-func (bh *BroadcastHub) Run() {
-	go func() {
-		for {
-			select {
-			case c := <-bh.register:
-				bh.connections[c] = true
-			case c := <-bh.unregister:
-				if _, ok := bh.connections[c]; ok {
-					delete(bh.connections, c)
-					close(c.send)
-				}
-			case m := <-bh.outgoing:
-				for c := range bh.connections {
-					select {
-					case c.send <- m:
-					default:
-						close(c.send)
-						delete(bh.connections, c)
-					}
-				}
-			}
-		}
-	}()
-}
-
-func (bh *BroadcastHub) Count() int {
-	return len(bh.connections)
-}
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package networking
+
+import (
+	"log"
+	"strings"
+	"sync"
+)
+
+type BroadcastHub struct {
+	connections map[*Connection]bool
+
+	unregister chan *Connection
+	lagging    chan *Connection
+
+	incoming chan []byte
+	outgoing *Outbox
+	done     chan bool
+
+	sync.RWMutex
+}
+
+func NewBroadcastHub(incoming chan []byte, outgoing *Outbox) *BroadcastHub {
+	return &BroadcastHub{
+		incoming:    incoming,
+		outgoing:    outgoing,
+		unregister:  make(chan *Connection, 1024),
+		lagging:     make(chan *Connection, 1024),
+		done:        make(chan bool),
+		connections: make(map[*Connection]bool),
+	}
+}
+
+func (bh *BroadcastHub) Run() {
+	go func() {
+		for {
+			select {
+			case c := <-bh.unregister:
+				bh.Lock()
+				if _, ok := bh.connections[c]; ok {
+					delete(bh.connections, c)
+					c.send.Close()
+				}
+				bh.Unlock()
+			case <-bh.outgoing.Ready():
+				for p := bh.outgoing.Pop(); p != nil; p = bh.outgoing.Pop() {
+					bh.broadcast(p)
+				}
+			case <-bh.done:
+				bh.Lock()
+				for c := range bh.connections {
+					c.send.Close()
+					delete(bh.connections, c)
+				}
+				bh.Unlock()
+
+				return
+			}
+		}
+	}()
+}
+
+func (bh *BroadcastHub) broadcast(p *Packet) {
+	bh.Lock()
+	defer bh.Unlock()
+
+	for c := range bh.connections {
+		if c.lagging || !p.Visible(c.name) {
+			continue
+		}
+
+		if !c.send.Push(p) {
+			log.Println("Connection: " + c.address + " lagging, resyncing")
+
+			c.lagging = true
+			c.send.Clear()
+
+			select {
+			case bh.lagging <- c:
+			default:
+			}
+		}
+	}
+}
+
+func (bh *BroadcastHub) Resync(c *Connection, state func() ([]byte, error)) bool {
+	bh.Lock()
+	defer bh.Unlock()
+
+	if !bh.connections[c] {
+		return false
+	}
+
+	c.lagging = false
+
+	bs, e := state()
+
+	if e != nil {
+		return false
+	}
+
+	return c.send.Push(&Packet{Priority: priorityCritical, Data: bs})
+}
+
+func (bh *BroadcastHub) Add(c *Connection) {
+	bh.Lock()
+	defer bh.Unlock()
+
+	bh.connections[c] = true
+}
+
+func (bh *BroadcastHub) Connections() []*Connection {
+	bh.RLock()
+	defer bh.RUnlock()
+
+	cs := make([]*Connection, 0, len(bh.connections))
+
+	for c := range bh.connections {
+		cs = append(cs, c)
+	}
+
+	return cs
+}
+
+func (bh *BroadcastHub) Find(name, host string) []*Connection {
+	bh.RLock()
+	defer bh.RUnlock()
+
+	found := []*Connection{}
+
+	for c := range bh.connections {
+		if (name != "" && strings.EqualFold(c.name, name)) || (host != "" && c.host == host) {
+			found = append(found, c)
+		}
+	}
+
+	return found
+}
+
+func (bh *BroadcastHub) Send(c *Connection, bs []byte) bool {
+	bh.RLock()
+	defer bh.RUnlock()
+
+	if !bh.connections[c] {
+		return false
+	}
+
+	return c.send.Push(&Packet{Priority: priorityCritical, Data: bs})
+}
+
+func (bh *BroadcastHub) Close() {
+	select {
+	case <-bh.done:
+	default:
+		close(bh.done)
+	}
+}
+
+func (bh *BroadcastHub) Count() int {
+	bh.RLock()
+	defer bh.RUnlock()
+
+	return len(bh.connections)
+}
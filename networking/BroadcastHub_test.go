@@ -0,0 +1,71 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package networking
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"testing"
+)
+
+func TestBroadcastResyncsLaggingConnection(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+
+	bh := NewBroadcastHub(make(chan []byte), NewOutbox(NewStats()))
+	c := NewConnection("session", "address", "slow", "host", false, bh, nil)
+	bh.Add(c)
+
+	for i := 0; i <= outboxHardLimit; i++ {
+		bh.broadcast(&Packet{Priority: priorityCritical, Data: []byte("update")})
+	}
+
+	if bh.Count() != 1 {
+		t.Fatal("slow connection was disconnected")
+	}
+
+	if !c.lagging || c.send.Len() != 0 {
+		t.Fatalf("lagging %v with %d queued, want a flagged and cleared queue", c.lagging, c.send.Len())
+	}
+
+	bh.broadcast(&Packet{Priority: priorityCritical, Data: []byte("update")})
+
+	if c.send.Len() != 0 {
+		t.Error("update queued while waiting for a resync")
+	}
+
+	if got := <-bh.lagging; got != c {
+		t.Fatal("lagging connection was not queued for a resync")
+	}
+
+	if bh.Resync(c, func() ([]byte, error) { return nil, errors.New("no state") }) {
+		t.Error("resync without state reported success")
+	}
+
+	if !bh.Resync(c, func() ([]byte, error) { return []byte("state"), nil }) {
+		t.Fatal("resync failed")
+	}
+
+	if p := c.send.Pop(); p == nil || string(p.Data) != "state" {
+		t.Fatal("state was not queued")
+	}
+
+	bh.broadcast(&Packet{Priority: priorityCritical, Data: []byte("update")})
+
+	if c.lagging || c.send.Len() != 1 {
+		t.Error("updates did not resume after the resync")
+	}
+}
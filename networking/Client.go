@@ -1,156 +1,275 @@
-/*
-Zahhak2, a Golang console game.
-Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
-This program is free software: you can redistribute it and/or modify
-it under the terms of the GNU General Public License as published by
-the Free Software Foundation, either version 3 of the License, or
-(at your option) any later version.
-This program is distributed in the hope that it will be useful,
-but WITHOUT ANY WARRANTY; without even the implied warranty of
-MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
-GNU General Public License for more details.
-You should have received a copy of the GNU General Public License
-along with this program.  If not, see <http://www.gnu.org/licenses/>.
-*/
-
-package networking
-
-import (
-	"errors"
-	"fmt"
-	"log"
-	"net/url"
-	"time"
-
-	"github.com/gorilla/websocket"
-
-	z "../common"
-)
-
-type Client struct {
-	address    string
-	connection *websocket.Conn
-	incoming   chan []byte
-	outgoing   chan []byte
-}
-
-func NewClient(host, port string, incoming, outgoing chan []byte) *Client {
-	address := host + ":" + port
-
-	return &Client{
-		address:  address,
-		incoming: incoming,
-		outgoing: outgoing,
-	}
-}
-
-func (c *Client) Run() {
-	defer c.recover()
-
-	var e error
-
-	u := url.URL{Scheme: "ws", Host: c.address, Path: "/"}
-
-	c.connection, _, e = websocket.DefaultDialer.Dial(u.String(), nil)
-
-	if e != nil {
-		z.LogError(errors.New("Client: " + e.Error()))
-	}
-
-	go c.writePump()
-	go c.readPump()
-}
-
-func (c *Client) readPump() {
-	defer c.recover()
-
-	defer func() {
-		log.Printf("Client: readPump(): Close()")
-
-		c.connection.Close()
-	}()
-
-	c.connection.SetPongHandler(func(string) error { c.connection.SetReadDeadline(time.Now().Add(pongWait)); return nil })
-
-	for {
-		_, message, err := c.connection.ReadMessage()
-
-		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway) {
-				z.LogError(errors.New("Client: readPump(): IsUnexpectedCloseError"))
-			} else {
-				z.LogError(errors.New("Client: readPump(): " + err.Error()))
-			}
-
-			break
-		}
-
-		c.incoming <- message
-	}
-}
-
-func (c *Client) writePump() {
-	defer c.recover()
-
-	ticker := time.NewTicker(pingPeriod)
-
-	defer func() {
-		log.Printf("Client: writePump(): Close()")
-		ticker.Stop()
-		c.connection.Close()
-	}()
-
-	for {
-		select {
-		case message, ok := <-c.outgoing:
-			if !ok {
-				z.LogError(errors.New("Client: writePump(): CloseMessage"))
-				c.write(websocket.CloseMessage, []byte{})
-
-				return
-			}
-
-			if err := c.write(websocket.TextMessage, message); err != nil {
-				z.LogError(errors.New("Client: writePump(): " + err.Error()))
-
-				return
-			}
-
-		case <-ticker.C:
-			if err := c.write(websocket.PingMessage, []byte{}); err != nil {
-				z.LogError(errors.New("Client: writePump(): " + err.Error()))
-
-				return
-			}
-		}
-	}
-}
-
-func (c *Client) write(mt int, payload []byte) error {
-	c.connection.SetWriteDeadline(time.Now().Add(writeWait))
-
-	return c.connection.WriteMessage(mt, payload)
-}
-
-func (c *Client) Count() int {
-	if c.connection == nil {
-		return 0
-	}
-
-	return 1
-}
-
-func (c *Client) recover() {
-	if r := recover(); r != nil {
-		e, ok := r.(error)
-
-		if !ok {
-			e = fmt.Errorf("%v", r)
-			e = errors.New("Client: " + e.Error())
-			z.LogPanic(e)
-		} else {
-			e = errors.New("Client: " + e.Error())
-			z.LogPanic(e)
-		}
-	}
-}
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package networking
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	z "../common"
+)
+
+type Client struct {
+	address     string
+	match       string
+	spectator   bool
+	identity    Credentials
+	security    Security
+	fingerprint string
+	connection  Transport
+	impairment  *Impairment
+	stats       *Stats
+	incoming    chan []byte
+	outgoing    *Outbox
+}
+
+func NewClient(host, port, match string, identity Credentials, security Security, spectator bool, incoming chan []byte, outgoing *Outbox) *Client {
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	address := net.JoinHostPort(host, port)
+
+	return &Client{
+		address:   address,
+		match:     match,
+		spectator: spectator,
+		identity:  identity,
+		security:  security,
+		incoming:  incoming,
+		outgoing:  outgoing,
+		stats:     NewStats(),
+	}
+}
+
+func (c *Client) Run() error {
+	defer c.recover()
+
+	var e error
+
+	u := url.URL{Scheme: "ws", Host: c.address, Path: "/"}
+
+	if c.security.TLS {
+		u.Scheme = "wss"
+	}
+
+	q := url.Values{}
+
+	if c.match != "" {
+		q.Set("match", c.match)
+	}
+
+	if c.spectator {
+		q.Set("spectator", "true")
+	}
+
+	q.Set("name", c.identity.Name)
+
+	u.RawQuery = q.Encode()
+
+	header := http.Header{}
+
+	if c.identity.Password != "" {
+		header.Set(HEADER_PASSWORD, c.identity.Password)
+	}
+
+	if c.identity.Token != "" {
+		header.Set(HEADER_TOKEN, c.identity.Token)
+	}
+
+	dialer := *websocket.DefaultDialer
+	dialer.TLSClientConfig = clientTLS(c.security.Fingerprint)
+
+	ws, _, e := dialer.Dial(u.String(), header)
+
+	if e != nil {
+		z.LogError(errors.New("Client: " + e.Error()))
+
+		var unknown x509.UnknownAuthorityError
+
+		if errors.As(e, &unknown) && unknown.Cert != nil {
+			return errors.New("server certificate is not trusted, pin its fingerprint to connect: " + Fingerprint(unknown.Cert.Raw))
+		}
+
+		return errors.New("could not connect to " + c.address + ": " + e.Error())
+	}
+
+	c.connection = transport(ws, c.impairment)
+
+	if conn, ok := c.connection.UnderlyingConn().(*tls.Conn); ok {
+		certs := conn.ConnectionState().PeerCertificates
+
+		if len(certs) > 0 {
+			c.fingerprint = Fingerprint(certs[0].Raw)
+		}
+	}
+
+	go c.writePump()
+	go c.readPump()
+
+	return nil
+}
+
+func (c *Client) readPump() {
+	defer c.recover()
+
+	defer func() {
+		log.Printf("Client: readPump(): Close()")
+
+		c.connection.Close()
+	}()
+
+	c.connection.SetPongHandler(func(payload string) error {
+		c.connection.SetReadDeadline(time.Now().Add(pongWait))
+		c.stats.Pong(payload)
+
+		return nil
+	})
+
+	for {
+		_, message, err := c.connection.ReadMessage()
+
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway) {
+				z.LogError(errors.New("Client: readPump(): IsUnexpectedCloseError"))
+			} else {
+				z.LogError(errors.New("Client: readPump(): " + err.Error()))
+			}
+
+			break
+		}
+
+		c.stats.In(len(message))
+
+		c.incoming <- message
+	}
+}
+
+func (c *Client) writePump() {
+	defer c.recover()
+
+	ticker := time.NewTicker(pingPeriod)
+	probe := time.NewTicker(latencyPeriod)
+
+	defer func() {
+		log.Printf("Client: writePump(): Close()")
+		ticker.Stop()
+		probe.Stop()
+		c.connection.Close()
+	}()
+
+	for {
+		select {
+		case <-c.outgoing.Ready():
+			if err := c.flush(); err != nil {
+				z.LogError(errors.New("Client: writePump(): " + err.Error()))
+
+				return
+			}
+
+		case <-c.outgoing.Done():
+			c.flush()
+
+			z.LogError(errors.New("Client: writePump(): CloseMessage"))
+			c.write(websocket.CloseMessage, []byte{})
+
+			return
+
+		case <-ticker.C:
+			if err := c.write(websocket.PingMessage, []byte{}); err != nil {
+				z.LogError(errors.New("Client: writePump(): " + err.Error()))
+
+				return
+			}
+
+		case <-probe.C:
+			if err := c.write(websocket.PingMessage, c.stats.Ping()); err != nil {
+				z.LogError(errors.New("Client: writePump(): " + err.Error()))
+
+				return
+			}
+		}
+	}
+}
+
+func (c *Client) flush() error {
+	for p := c.outgoing.Pop(); p != nil; p = c.outgoing.Pop() {
+		if err := c.write(websocket.TextMessage, p.Data); err != nil {
+			return err
+		}
+
+		c.stats.Out(len(p.Data))
+	}
+
+	return nil
+}
+
+func (c *Client) write(mt int, payload []byte) error {
+	c.connection.SetWriteDeadline(time.Now().Add(writeWait))
+
+	return c.connection.WriteMessage(mt, payload)
+}
+
+func (c *Client) Count() int {
+	if c.connection == nil {
+		return 0
+	}
+
+	return 1
+}
+
+func (c *Client) Impair(i *Impairment) {
+	c.impairment = i
+}
+
+func (c *Client) Fingerprint() string {
+	return c.fingerprint
+}
+
+func (c *Client) Diagnostics() *Diagnostics {
+	return c.stats.Diagnostics("Server", c.address, c.outgoing.Len())
+}
+
+func (c *Client) Close() {
+	if c.connection == nil {
+		return
+	}
+
+	c.write(websocket.CloseMessage, []byte{})
+	c.connection.Close()
+}
+
+func (c *Client) recover() {
+	if r := recover(); r != nil {
+		e, ok := r.(error)
+
+		if !ok {
+			e = fmt.Errorf("%v", r)
+			e = errors.New("Client: " + e.Error())
+			z.LogPanic(e)
+		} else {
+			e = errors.New("Client: " + e.Error())
+			z.LogPanic(e)
+		}
+	}
+}
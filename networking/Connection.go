@@ -1,120 +1,244 @@
-/*
-Zahhak2, a Golang console game.
-Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
-This program is free software: you can redistribute it and/or modify
-it under the terms of the GNU General Public License as published by
-the Free Software Foundation, either version 3 of the License, or
-(at your option) any later version.
-This program is distributed in the hope that it will be useful,
-but WITHOUT ANY WARRANTY; without even the implied warranty of
-MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
-GNU General Public License for more details.
-You should have received a copy of the GNU General Public License
-along with this program.  If not, see <http://www.gnu.org/licenses/>.
-*/
-
-package networking
-
-import (
-	"errors"
-	"fmt"
-	"log"
-	"time"
-
-	"github.com/gorilla/websocket"
-
-	z "../common"
-)
-
-type Connection struct {
-	address string
-	hub     *BroadcastHub
-	ws      *websocket.Conn
-	send    chan []byte
-}
-
-func (c *Connection) readPump() {
-	defer c.recover()
-
-	defer func() {
-		log.Println("Connection: " + c.address + " readPump(): ws.Close()")
-		c.hub.unregister <- c
-		c.ws.Close()
-	}()
-
-	c.ws.SetReadDeadline(time.Now().Add(pongWait))
-	c.ws.SetPongHandler(func(string) error { c.ws.SetReadDeadline(time.Now().Add(pongWait)); return nil })
-
-	for {
-		_, message, err := c.ws.ReadMessage()
-
-		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway) {
-				z.LogError(errors.New("Connection: " + c.address + " " + " websocket.IsUnexpectedCloseError"))
-			} else {
-				z.LogError(errors.New("Connection: " + c.address + " " + err.Error()))
-			}
-
-			break
-		}
-
-		c.hub.incoming <- message
-	}
-}
-
-func (c *Connection) writePump() {
-	defer c.recover()
-
-	ticker := time.NewTicker(pingPeriod)
-
-	defer func() {
-		log.Println("Connection: " + c.address + " writePump(): ws.Close()")
-		ticker.Stop()
-		c.ws.Close()
-	}()
-
-	for {
-		select {
-		case message, ok := <-c.send:
-			if !ok {
-				z.LogError(errors.New("Connection: " + c.address + " " + " websocket.CloseMessage"))
-				c.write(websocket.CloseMessage, []byte{})
-
-				return
-			}
-
-			if err := c.write(websocket.TextMessage, message); err != nil {
-				z.LogError(errors.New("Connection: " + c.address + " " + err.Error()))
-
-				return
-			}
-		case <-ticker.C:
-			if err := c.write(websocket.PingMessage, []byte{}); err != nil {
-				z.LogError(errors.New("Connection: " + c.address + " " + err.Error()))
-
-				return
-			}
-		}
-	}
-}
-
-func (c *Connection) write(mt int, payload []byte) error {
-	c.ws.SetWriteDeadline(time.Now().Add(writeWait))
-
-	return c.ws.WriteMessage(mt, payload)
-}
-
-func (c *Connection) recover() {
-	if r := recover(); r != nil {
-		e, ok := r.(error)
-
-		if !ok {
-			e = fmt.Errorf("%v", r)
-			e = errors.New("Connection: " + c.address + " " + e.Error())
-			z.LogPanic(e)
-		} else {
-			e = errors.New("Connection: " + c.address + " " + e.Error())
-			z.LogPanic(e)
-		}
-	}
-}
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package networking
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	z "../common"
+)
+
+type Connection struct {
+	id        string
+	session   string
+	address   string
+	name      string
+	host      string
+	spectator bool
+	hub       *BroadcastHub
+	ws        Transport
+	send      *Outbox
+	stats     *Stats
+
+	window   time.Time
+	messages int
+	lagging  bool
+}
+
+func NewConnection(session, address, name, host string, spectator bool, hub *BroadcastHub, ws Transport) *Connection {
+	stats := NewStats()
+
+	return &Connection{
+		id:        z.UUID(),
+		session:   session,
+		address:   address,
+		name:      name,
+		host:      host,
+		spectator: spectator,
+		hub:       hub,
+		ws:        ws,
+		send:      NewOutbox(stats),
+		stats:     stats,
+	}
+}
+
+func (c *Connection) readPump() {
+	defer c.recover()
+
+	defer func() {
+		log.Println("Connection: " + c.address + " readPump(): ws.Close()")
+		c.hub.unregister <- c
+		c.ws.Close()
+	}()
+
+	c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	c.ws.SetPongHandler(func(payload string) error {
+		c.ws.SetReadDeadline(time.Now().Add(pongWait))
+		c.stats.Pong(payload)
+
+		return nil
+	})
+
+	for {
+		_, message, err := c.ws.ReadMessage()
+
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway) {
+				z.LogError(errors.New("Connection: " + c.address + " " + " websocket.IsUnexpectedCloseError"))
+			} else {
+				z.LogError(errors.New("Connection: " + c.address + " " + err.Error()))
+			}
+
+			break
+		}
+
+		c.stats.In(len(message))
+
+		if !c.limit() {
+			z.LogError(errors.New("Connection: " + c.address + " flooding, closing"))
+
+			msg := NewRejection(z.REJECT_FLOOD, "too many messages").Message()
+			msg.Params["Session"] = c.session
+			bs, _ := msg.JSON()
+			c.hub.Send(c, bs)
+
+			time.Sleep(1 * time.Second)
+
+			break
+		}
+
+		m, e := c.stamp(message)
+
+		if e != nil {
+			z.LogError(errors.New("Connection: " + c.address + " malformed message: " + e.Error()))
+
+			continue
+		}
+
+		if c.messages > messageRate && coalesced[m.Action] {
+			c.stats.Drop()
+
+			continue
+		}
+
+		bs, _ := json.Marshal(m)
+
+		c.hub.incoming <- bs
+	}
+}
+
+func (c *Connection) limit() bool {
+	now := time.Now()
+
+	if now.Sub(c.window) >= time.Second {
+		c.window = now
+		c.messages = 0
+	}
+
+	c.messages++
+
+	if c.messages == messageRate+1 {
+		log.Println("Connection: " + c.address + " over the message rate, dropping position updates")
+	}
+
+	return c.messages <= messageRate*floodFactor
+}
+
+func (c *Connection) stamp(message []byte) (*z.Message, error) {
+	m := &z.Message{}
+
+	if e := json.Unmarshal(message, m); e != nil {
+		return nil, e
+	}
+
+	if m.Params == nil {
+		m.Params = map[string]string{}
+	}
+
+	m.Params["Sender"] = c.id
+	m.Params["SenderName"] = c.name
+	m.Params["Spectator"] = strconv.FormatBool(c.spectator)
+
+	return m, nil
+}
+
+func (c *Connection) writePump() {
+	defer c.recover()
+
+	ticker := time.NewTicker(pingPeriod)
+	probe := time.NewTicker(latencyPeriod)
+
+	defer func() {
+		log.Println("Connection: " + c.address + " writePump(): ws.Close()")
+		ticker.Stop()
+		probe.Stop()
+		c.ws.Close()
+	}()
+
+	for {
+		select {
+		case <-c.send.Ready():
+			if err := c.flush(); err != nil {
+				z.LogError(errors.New("Connection: " + c.address + " " + err.Error()))
+
+				return
+			}
+		case <-c.send.Done():
+			c.flush()
+
+			z.LogError(errors.New("Connection: " + c.address + " " + " websocket.CloseMessage"))
+			c.write(websocket.CloseMessage, []byte{})
+
+			return
+		case <-ticker.C:
+			if err := c.write(websocket.PingMessage, []byte{}); err != nil {
+				z.LogError(errors.New("Connection: " + c.address + " " + err.Error()))
+
+				return
+			}
+		case <-probe.C:
+			if err := c.write(websocket.PingMessage, c.stats.Ping()); err != nil {
+				z.LogError(errors.New("Connection: " + c.address + " " + err.Error()))
+
+				return
+			}
+		}
+	}
+}
+
+func (c *Connection) flush() error {
+	for p := c.send.Pop(); p != nil; p = c.send.Pop() {
+		if err := c.write(websocket.TextMessage, p.Data); err != nil {
+			return err
+		}
+
+		c.stats.Out(len(p.Data))
+	}
+
+	return nil
+}
+
+func (c *Connection) write(mt int, payload []byte) error {
+	c.ws.SetWriteDeadline(time.Now().Add(writeWait))
+
+	return c.ws.WriteMessage(mt, payload)
+}
+
+func (c *Connection) Diagnostics() *Diagnostics {
+	return c.stats.Diagnostics(c.name, c.address, c.send.Len())
+}
+
+func (c *Connection) recover() {
+	if r := recover(); r != nil {
+		e, ok := r.(error)
+
+		if !ok {
+			e = fmt.Errorf("%v", r)
+			e = errors.New("Connection: " + c.address + " " + e.Error())
+			z.LogPanic(e)
+		} else {
+			e = errors.New("Connection: " + c.address + " " + e.Error())
+			z.LogPanic(e)
+		}
+	}
+}
@@ -1,26 +1,82 @@
-/*
-Zahhak2, a Golang console game.
-Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
-This program is free software: you can redistribute it and/or modify
-it under the terms of the GNU General Public License as published by
-the Free Software Foundation, either version 3 of the License, or
-(at your option) any later version.
-This program is distributed in the hope that it will be useful,
-but WITHOUT ANY WARRANTY; without even the implied warranty of
-MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
-GNU General Public License for more details.
-You should have received a copy of the GNU General Public License
-along with this program.  If not, see <http://www.gnu.org/licenses/>.
-*/
-
-package networking
-
-import (
-	"time"
-)
-
-const (
-	writeWait  = 60 * time.Second
-	pongWait   = 60 * time.Second
-	pingPeriod = (pongWait * 9) / 10
-)
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package networking
+
+import (
+	"time"
+)
+
+const (
+	writeWait  = 60 * time.Second
+	pongWait   = 60 * time.Second
+	pingPeriod = (pongWait * 9) / 10
+)
+
+const (
+	latencyPeriod = 2 * time.Second
+)
+
+const (
+	messageRate = 100
+	floodFactor = 3
+)
+
+const (
+	priorityCritical = iota
+	priorityCosmetic
+	priorities
+)
+
+const (
+	outboxSoftLimit = 256
+	outboxHardLimit = 4096
+)
+
+var cosmetic = map[string]bool{
+	"Announce": true,
+	"Sfx":      true,
+}
+
+var coalesced = map[string]bool{
+	"SetPosition": true,
+}
+
+const (
+	reorderDelay = 20 * time.Millisecond
+)
+
+const (
+	certLifetime = 365 * 24 * time.Hour
+)
+
+const (
+	beaconPeriod = 2 * time.Second
+	beaconTTL    = 3 * beaconPeriod
+)
+
+const (
+	PROTOCOL_VERSION    = 1
+	DISCOVERY_GROUP     = "239.19.47.1:1948"
+	DISCOVERY_BROADCAST = "255.255.255.255:1948"
+	DISCOVERY_LOOPBACK  = "127.0.0.1:1948"
+)
+
+var DISCOVERY_TARGETS = []string{DISCOVERY_GROUP, DISCOVERY_BROADCAST, DISCOVERY_LOOPBACK}
+
+const (
+	HEADER_PASSWORD = "Zahhak-Password"
+	HEADER_TOKEN    = "Zahhak-Token"
+)
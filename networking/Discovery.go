@@ -0,0 +1,264 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package networking
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	z "../common"
+)
+
+type Beacon struct {
+	Protocol    int
+	Session     string
+	Name        string
+	Match       string
+	Host        string
+	Port        string
+	Players     int
+	WorldWidth  int
+	WorldHeight int
+	Phase       string
+	TLS         bool
+	Fingerprint string
+
+	Address string    `json:"-"`
+	Seen    time.Time `json:"-"`
+}
+
+func (b *Beacon) Compatible() bool {
+	return b.Protocol == PROTOCOL_VERSION
+}
+
+func (b *Beacon) Key() string {
+	return net.JoinHostPort(b.Address, b.Port)
+}
+
+type Announcer struct {
+	targets []string
+	info    func() *Beacon
+	done    chan bool
+}
+
+func NewAnnouncer(targets []string, info func() *Beacon) *Announcer {
+	return &Announcer{
+		targets: targets,
+		info:    info,
+		done:    make(chan bool),
+	}
+}
+
+func (a *Announcer) Run() error {
+	conn, e := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4zero, Port: 0})
+
+	if e != nil {
+		return errors.New("could not open discovery socket: " + e.Error())
+	}
+
+	addrs := []*net.UDPAddr{}
+
+	for _, target := range a.targets {
+		addr, e := net.ResolveUDPAddr("udp4", target)
+
+		if e != nil {
+			conn.Close()
+
+			return errors.New("invalid discovery address " + target + ": " + e.Error())
+		}
+
+		addrs = append(addrs, addr)
+	}
+
+	go func() {
+		defer a.recover()
+		defer conn.Close()
+
+		ticker := time.NewTicker(beaconPeriod)
+		defer ticker.Stop()
+
+		for {
+			b := a.info()
+			b.Protocol = PROTOCOL_VERSION
+
+			bs, e := json.Marshal(b)
+
+			if e == nil {
+				for _, addr := range addrs {
+					conn.WriteToUDP(bs, addr)
+				}
+			}
+
+			select {
+			case <-a.done:
+				return
+
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (a *Announcer) Close() {
+	close(a.done)
+}
+
+func (a *Announcer) recover() {
+	if r := recover(); r != nil {
+		e, ok := r.(error)
+
+		if !ok {
+			e = fmt.Errorf("%v", r)
+		}
+
+		z.LogPanic(errors.New("Announcer: " + e.Error()))
+	}
+}
+
+type Browser struct {
+	sync.RWMutex
+
+	targets []string
+	conn    *net.UDPConn
+	games   map[string]*Beacon
+}
+
+func NewBrowser(targets []string) *Browser {
+	return &Browser{
+		targets: targets,
+		games:   map[string]*Beacon{},
+	}
+}
+
+func (b *Browser) Run() error {
+	var group *net.UDPAddr
+
+	port := 0
+
+	for _, target := range b.targets {
+		addr, e := net.ResolveUDPAddr("udp4", target)
+
+		if e != nil {
+			return errors.New("invalid discovery address " + target + ": " + e.Error())
+		}
+
+		if port != 0 && addr.Port != port {
+			return errors.New("discovery addresses must share one port")
+		}
+
+		port = addr.Port
+
+		if addr.IP.IsMulticast() && group == nil {
+			group = addr
+		}
+	}
+
+	var e error
+
+	if group != nil {
+		b.conn, e = net.ListenMulticastUDP("udp4", nil, group)
+	} else {
+		b.conn, e = net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4zero, Port: port})
+	}
+
+	if e != nil {
+		return fmt.Errorf("could not listen for games on port %d: %s", port, e.Error())
+	}
+
+	go func() {
+		defer b.recover()
+
+		buf := make([]byte, 2048)
+
+		for {
+			n, from, e := b.conn.ReadFromUDP(buf)
+
+			if e != nil {
+				log.Println("Browser: " + e.Error())
+
+				return
+			}
+
+			beacon := &Beacon{}
+
+			if e := json.Unmarshal(buf[:n], beacon); e != nil {
+				continue
+			}
+
+			beacon.Address = from.IP.String()
+			beacon.Seen = time.Now()
+
+			b.Lock()
+
+			if old, ok := b.games[beacon.Session]; ok && from.IP.IsLoopback() && old.Address != beacon.Address {
+				beacon.Address = old.Address
+			}
+
+			b.games[beacon.Session] = beacon
+
+			b.Unlock()
+		}
+	}()
+
+	return nil
+}
+
+func (b *Browser) Games() []*Beacon {
+	b.Lock()
+	defer b.Unlock()
+
+	games := []*Beacon{}
+
+	for key, game := range b.games {
+		if time.Since(game.Seen) > beaconTTL {
+			delete(b.games, key)
+
+			continue
+		}
+
+		games = append(games, game)
+	}
+
+	sort.Slice(games, func(i, j int) bool { return games[i].Key() < games[j].Key() })
+
+	return games
+}
+
+func (b *Browser) Close() {
+	if b.conn != nil {
+		b.conn.Close()
+	}
+}
+
+func (b *Browser) recover() {
+	if r := recover(); r != nil {
+		e, ok := r.(error)
+
+		if !ok {
+			e = fmt.Errorf("%v", r)
+		}
+
+		z.LogPanic(errors.New("Browser: " + e.Error()))
+	}
+}
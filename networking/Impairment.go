@@ -0,0 +1,392 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package networking
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type Transport interface {
+	ReadMessage() (int, []byte, error)
+	WriteMessage(int, []byte) error
+	SetReadDeadline(time.Time) error
+	SetWriteDeadline(time.Time) error
+	SetPongHandler(func(string) error)
+	RemoteAddr() net.Addr
+	UnderlyingConn() net.Conn
+	Close() error
+}
+
+type Impairment struct {
+	Latency   time.Duration
+	Jitter    time.Duration
+	Loss      float64
+	Reorder   float64
+	Bandwidth int
+	Seed      int64
+
+	links int64
+}
+
+var simulated *Impairment
+
+func Simulate(i *Impairment) {
+	simulated = i
+
+	if i != nil {
+		log.Println("Networking: simulating " + i.String())
+	}
+}
+
+func ParseImpairment(spec string) (*Impairment, error) {
+	i := &Impairment{Seed: 1}
+
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+
+		if field == "" {
+			continue
+		}
+
+		kv := strings.SplitN(field, "=", 2)
+
+		if len(kv) != 2 {
+			return nil, errors.New("impairment " + field + " is not key=value")
+		}
+
+		var e error
+
+		switch strings.ToLower(kv[0]) {
+		case "latency":
+			i.Latency, e = time.ParseDuration(kv[1])
+
+		case "jitter":
+			i.Jitter, e = time.ParseDuration(kv[1])
+
+		case "loss":
+			i.Loss, e = strconv.ParseFloat(kv[1], 64)
+
+		case "reorder":
+			i.Reorder, e = strconv.ParseFloat(kv[1], 64)
+
+		case "bandwidth":
+			i.Bandwidth, e = strconv.Atoi(kv[1])
+
+		case "seed":
+			i.Seed, e = strconv.ParseInt(kv[1], 10, 64)
+
+		default:
+			return nil, errors.New("unknown impairment " + kv[0])
+		}
+
+		if e != nil {
+			return nil, errors.New("impairment " + field + ": " + e.Error())
+		}
+	}
+
+	if i.Latency < 0 || i.Jitter < 0 || i.Bandwidth < 0 {
+		return nil, errors.New("impairment values must not be negative")
+	}
+
+	if i.Loss < 0 || i.Loss > 1 || i.Reorder < 0 || i.Reorder > 1 {
+		return nil, errors.New("loss and reorder must be between 0 and 1")
+	}
+
+	return i, nil
+}
+
+func (i *Impairment) String() string {
+	return fmt.Sprintf("latency=%v,jitter=%v,loss=%g,reorder=%g,bandwidth=%d,seed=%d", i.Latency, i.Jitter, i.Loss, i.Reorder, i.Bandwidth, i.Seed)
+}
+
+func transport(ws *websocket.Conn, i *Impairment) Transport {
+	if i == nil {
+		i = simulated
+	}
+
+	if i == nil {
+		return ws
+	}
+
+	return Impair(ws, i)
+}
+
+type frame struct {
+	kind int
+	data []byte
+	err  error
+	due  time.Time
+	seq  int64
+}
+
+type frames []*frame
+
+func (fs frames) Len() int { return len(fs) }
+
+func (fs frames) Less(i, j int) bool {
+	if fs[i].due.Equal(fs[j].due) {
+		return fs[i].seq < fs[j].seq
+	}
+
+	return fs[i].due.Before(fs[j].due)
+}
+
+func (fs frames) Swap(i, j int) { fs[i], fs[j] = fs[j], fs[i] }
+
+func (fs *frames) Push(x interface{}) { *fs = append(*fs, x.(*frame)) }
+
+func (fs *frames) Pop() interface{} {
+	old := *fs
+	f := old[len(old)-1]
+	*fs = old[:len(old)-1]
+
+	return f
+}
+
+type line struct {
+	sync.Mutex
+
+	impairment *Impairment
+	random     *rand.Rand
+	queue      frames
+	seq        int64
+	last       time.Time
+	busy       time.Time
+	wake       chan bool
+	done       chan bool
+	deliver    func(*frame)
+}
+
+func newLine(i *Impairment, seed int64, done chan bool, deliver func(*frame)) *line {
+	l := &line{
+		impairment: i,
+		random:     rand.New(rand.NewSource(seed)),
+		wake:       make(chan bool, 1),
+		done:       done,
+		deliver:    deliver,
+	}
+
+	go l.run()
+
+	return l
+}
+
+func (l *line) schedule(f *frame, lossy bool) {
+	l.Lock()
+	defer l.Unlock()
+
+	i := l.impairment
+
+	if lossy && l.random.Float64() < i.Loss {
+		return
+	}
+
+	now := time.Now()
+
+	if l.busy.Before(now) {
+		l.busy = now
+	}
+
+	if i.Bandwidth > 0 {
+		l.busy = l.busy.Add(time.Duration(len(f.data)) * time.Second / time.Duration(i.Bandwidth))
+	}
+
+	due := l.busy.Add(i.Latency)
+
+	if i.Jitter > 0 {
+		due = due.Add(time.Duration(l.random.Int63n(int64(i.Jitter) + 1)))
+	}
+
+	if lossy && l.random.Float64() < i.Reorder {
+		due = due.Add(i.Latency + i.Jitter + reorderDelay)
+	} else {
+		if due.Before(l.last) {
+			due = l.last
+		}
+
+		l.last = due
+	}
+
+	l.seq++
+	f.due, f.seq = due, l.seq
+
+	heap.Push(&l.queue, f)
+
+	select {
+	case l.wake <- true:
+	default:
+	}
+}
+
+func (l *line) next() (*frame, time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+
+	if len(l.queue) == 0 {
+		return nil, -1
+	}
+
+	if wait := time.Until(l.queue[0].due); wait > 0 {
+		return nil, wait
+	}
+
+	return heap.Pop(&l.queue).(*frame), 0
+}
+
+func (l *line) run() {
+	for {
+		f, wait := l.next()
+
+		if f != nil {
+			l.deliver(f)
+
+			continue
+		}
+
+		var timer <-chan time.Time
+
+		if wait > 0 {
+			timer = time.After(wait)
+		}
+
+		select {
+		case <-timer:
+		case <-l.wake:
+		case <-l.done:
+			return
+		}
+	}
+}
+
+type Impaired struct {
+	*websocket.Conn
+
+	in       *line
+	out      *line
+	incoming chan *frame
+	reading  sync.Once
+	writing  sync.Mutex
+	done     chan bool
+	closing  sync.Once
+	failing  sync.Mutex
+	failed   error
+}
+
+func Impair(ws *websocket.Conn, i *Impairment) *Impaired {
+	seed := i.Seed + atomic.AddInt64(&i.links, 1)*2
+
+	t := &Impaired{
+		Conn:     ws,
+		incoming: make(chan *frame, 1024),
+		done:     make(chan bool),
+	}
+
+	t.in = newLine(i, seed, t.done, t.receive)
+	t.out = newLine(i, seed+1, t.done, t.send)
+
+	return t
+}
+
+func (t *Impaired) ReadMessage() (int, []byte, error) {
+	t.reading.Do(func() { go t.read() })
+
+	select {
+	case f := <-t.incoming:
+		return f.kind, f.data, f.err
+
+	case <-t.done:
+		return 0, nil, errors.New("impaired transport closed")
+	}
+}
+
+func (t *Impaired) read() {
+	for {
+		kind, data, e := t.Conn.ReadMessage()
+
+		if e != nil {
+			t.in.schedule(&frame{err: e}, false)
+
+			return
+		}
+
+		t.in.schedule(&frame{kind: kind, data: data}, kind == websocket.TextMessage)
+	}
+}
+
+func (t *Impaired) receive(f *frame) {
+	select {
+	case t.incoming <- f:
+	case <-t.done:
+	}
+}
+
+func (t *Impaired) WriteMessage(kind int, data []byte) error {
+	if e := t.failure(); e != nil {
+		return e
+	}
+
+	if kind == websocket.CloseMessage {
+		t.send(&frame{kind: kind, data: data})
+
+		return nil
+	}
+
+	t.out.schedule(&frame{kind: kind, data: data}, kind == websocket.TextMessage)
+
+	return nil
+}
+
+func (t *Impaired) send(f *frame) {
+	t.writing.Lock()
+	defer t.writing.Unlock()
+
+	t.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+
+	if e := t.Conn.WriteMessage(f.kind, f.data); e != nil {
+		t.failing.Lock()
+		t.failed = e
+		t.failing.Unlock()
+	}
+}
+
+func (t *Impaired) failure() error {
+	t.failing.Lock()
+	defer t.failing.Unlock()
+
+	return t.failed
+}
+
+func (t *Impaired) SetWriteDeadline(time.Time) error {
+	return nil
+}
+
+func (t *Impaired) Close() error {
+	t.closing.Do(func() { close(t.done) })
+
+	return t.Conn.Close()
+}
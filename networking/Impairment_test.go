@@ -0,0 +1,189 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package networking
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	z "../common"
+)
+
+type arrival struct {
+	id int
+	at time.Time
+}
+
+func freePort(t *testing.T) string {
+	l, e := net.Listen("tcp", "127.0.0.1:0")
+
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	defer l.Close()
+
+	return strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+}
+
+func probe(t *testing.T, spec string, n int) ([]arrival, []time.Time) {
+	log.SetOutput(ioutil.Discard)
+
+	i, e := ParseImpairment(spec)
+
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	port := freePort(t)
+	incoming := make(chan []byte, n)
+
+	em := z.NewEventManager()
+	em.On("NewClient", func(spectator bool, name, password, token string) (string, bool) {
+		return `{"Class":"Game","Action":"Welcome"}`, true
+	})
+
+	match := NewServer("127.0.0.1", port).Open(em, z.UUID(), "Main", incoming, NewOutbox(NewStats()))
+
+	if e := match.Run(); e != nil {
+		t.Fatal(e)
+	}
+
+	outgoing := NewOutbox(NewStats())
+	client := NewClient("127.0.0.1", port, "", Credentials{Name: "tester"}, Security{}, false, make(chan []byte, n), outgoing)
+	client.Impair(i)
+
+	if e := client.Run(); e != nil {
+		t.Fatal(e)
+	}
+
+	defer client.Close()
+
+	done := make(chan bool)
+	result := make(chan []arrival)
+
+	go func() {
+		arrivals := []arrival{}
+
+		for {
+			select {
+			case bs := <-incoming:
+				m := &z.Message{}
+				json.Unmarshal(bs, m)
+				id, _ := strconv.Atoi(m.ID)
+				arrivals = append(arrivals, arrival{id: id, at: time.Now()})
+
+			case <-done:
+				result <- arrivals
+
+				return
+			}
+		}
+	}()
+
+	sent := make([]time.Time, n)
+
+	for k := range sent {
+		bs, _ := z.NewMessage("Probe", strconv.Itoa(k), "Probe").JSON()
+		sent[k] = time.Now()
+		outgoing.Push(&Packet{Priority: priorityCritical, Data: bs})
+
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	time.Sleep(2*(i.Latency+i.Jitter) + reorderDelay + 500*time.Millisecond)
+	close(done)
+
+	return <-result, sent
+}
+
+func TestImpairmentLatency(t *testing.T) {
+	arrivals, sent := probe(t, "latency=80ms,jitter=20ms,seed=1", 20)
+
+	if len(arrivals) != len(sent) {
+		t.Fatalf("%d of %d messages arrived without loss", len(arrivals), len(sent))
+	}
+
+	for k, a := range arrivals {
+		if a.id != k {
+			t.Errorf("message %d arrived as %d without reordering", a.id, k)
+		}
+
+		if delay := a.at.Sub(sent[a.id]); delay < 80*time.Millisecond {
+			t.Errorf("message %d arrived after %v, before the latency", a.id, delay)
+		}
+	}
+}
+
+func TestImpairmentLoss(t *testing.T) {
+	lost := func() map[int]bool {
+		arrivals, sent := probe(t, "loss=0.3,seed=7", 80)
+		missing := map[int]bool{}
+
+		for id := range sent {
+			missing[id] = true
+		}
+
+		for _, a := range arrivals {
+			delete(missing, a.id)
+		}
+
+		return missing
+	}
+
+	first := lost()
+
+	if len(first) == 0 || len(first) > 40 {
+		t.Fatalf("lost %d of 80 messages with loss=0.3", len(first))
+	}
+
+	second := lost()
+
+	if len(second) != len(first) {
+		t.Fatalf("lost %d then %d messages with the same seed", len(first), len(second))
+	}
+
+	for id := range first {
+		if !second[id] {
+			t.Errorf("message %d lost only in the first run with the same seed", id)
+		}
+	}
+}
+
+func TestImpairmentReorder(t *testing.T) {
+	arrivals, sent := probe(t, "latency=20ms,reorder=0.2,seed=3", 60)
+
+	if len(arrivals) != len(sent) {
+		t.Fatalf("%d of %d messages arrived without loss", len(arrivals), len(sent))
+	}
+
+	late := 0
+
+	for k := 1; k < len(arrivals); k++ {
+		if arrivals[k].id < arrivals[k-1].id {
+			late++
+		}
+	}
+
+	if late == 0 {
+		t.Error("no message arrived out of order with reorder=0.2")
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package networking
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	z "../common"
+)
+
+type Match struct {
+	session      string
+	name         string
+	created      time.Time
+	server       *Server
+	hub          *BroadcastHub
+	eventManager *z.EventManager
+}
+
+type MatchInfo struct {
+	Session string
+	Name    string
+	Players int
+}
+
+func NewMatch(server *Server, eventManager *z.EventManager, session, name string, incoming chan []byte, outgoing *Outbox) *Match {
+	hub := NewBroadcastHub(incoming, outgoing)
+
+	return &Match{
+		session:      session,
+		name:         name,
+		created:      time.Now(),
+		server:       server,
+		hub:          hub,
+		eventManager: eventManager,
+	}
+}
+
+func (m *Match) Run() error {
+	if e := m.server.Run(); e != nil {
+		return e
+	}
+
+	m.hub.Run()
+	m.server.add(m)
+
+	go m.resync()
+
+	return nil
+}
+
+func (m *Match) Server() *Server {
+	return m.server
+}
+
+func (m *Match) Count() int {
+	return m.hub.Count()
+}
+
+func (m *Match) Close() {
+	m.server.remove(m)
+	m.hub.Close()
+}
+
+func (m *Match) Drop(id string, rejection *Rejection) bool {
+	for _, c := range m.hub.Connections() {
+		if c.id == id {
+			log.Println("Server: dropping " + c.name + " " + c.address + " from " + m.name + ": " + rejection.Reason)
+
+			m.disconnect(c, rejection)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *Match) disconnect(c *Connection, rejection *Rejection) {
+	msg := rejection.Message()
+	msg.Params["Session"] = m.session
+	bs, _ := msg.JSON()
+
+	m.hub.Send(c, bs)
+
+	ws := c.ws
+	time.AfterFunc(1*time.Second, func() { ws.Close() })
+}
+
+func (m *Match) resync() {
+	for {
+		select {
+		case c := <-m.hub.lagging:
+			ok := m.hub.Resync(c, func() ([]byte, error) {
+				p, e := m.eventManager.Fire("Snapshot", c.name)
+
+				if e != nil {
+					return nil, e
+				}
+
+				return []byte(p[0].String()), nil
+			})
+
+			if !ok {
+				log.Println("Server: could not resync " + c.name + " " + c.address)
+			}
+
+		case <-m.hub.done:
+			return
+		}
+	}
+}
+
+func (m *Match) Diagnostics() []*Diagnostics {
+	cs := m.hub.Connections()
+	ds := make([]*Diagnostics, 0, len(cs))
+
+	for _, c := range cs {
+		ds = append(ds, c.Diagnostics())
+	}
+
+	sort.Slice(ds, func(i, j int) bool { return ds[i].Name < ds[j].Name })
+
+	return ds
+}
+
+func (m *Match) Info() *MatchInfo {
+	return &MatchInfo{
+		Session: m.session,
+		Name:    m.name,
+		Players: m.Count(),
+	}
+}
+
+func ListMatches(host, port string, security Security) ([]*MatchInfo, error) {
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+
+	u := url.URL{Scheme: "http", Host: net.JoinHostPort(host, port), Path: "/matches"}
+	client := &http.Client{Timeout: 3 * time.Second}
+
+	if security.TLS {
+		u.Scheme = "https"
+		client.Transport = &http.Transport{TLSClientConfig: clientTLS(security.Fingerprint)}
+	}
+
+	r, e := client.Get(u.String())
+
+	if e != nil {
+		return nil, e
+	}
+
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return nil, errors.New("server answered " + r.Status)
+	}
+
+	matches := []*MatchInfo{}
+
+	if e := json.NewDecoder(r.Body).Decode(&matches); e != nil {
+		return nil, e
+	}
+
+	return matches, nil
+}
@@ -0,0 +1,232 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package networking
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+
+	z "../common"
+)
+
+type Packet struct {
+	Priority int
+	Key      string
+	Data     []byte
+	To       string
+	Hidden   map[string]bool
+}
+
+type entry struct {
+	packet *Packet
+}
+
+type Outbox struct {
+	sync.Mutex
+
+	queues [priorities][]*entry
+	keys   map[string]*entry
+	size   int
+	stats  *Stats
+	ready  chan bool
+	done   chan bool
+}
+
+func NewPacket(m *z.Message) (*Packet, error) {
+	bs, e := json.Marshal(m)
+
+	if e != nil {
+		return nil, e
+	}
+
+	p := &Packet{Priority: priorityCritical, Data: bs, To: strings.ToLower(m.To)}
+
+	if len(m.Hidden) > 0 {
+		p.Hidden = map[string]bool{}
+
+		for _, name := range m.Hidden {
+			p.Hidden[strings.ToLower(name)] = true
+		}
+	}
+
+	if cosmetic[m.Action] {
+		p.Priority = priorityCosmetic
+	}
+
+	if coalesced[m.Action] && p.To == "" {
+		p.Key = m.Class + "/" + m.ID + "/" + m.Action
+	}
+
+	return p, nil
+}
+
+func (p *Packet) Visible(name string) bool {
+	name = strings.ToLower(name)
+
+	if p.To != "" {
+		return p.To == name
+	}
+
+	return !p.Hidden[name]
+}
+
+func NewOutbox(stats *Stats) *Outbox {
+	return &Outbox{
+		keys:  map[string]*entry{},
+		stats: stats,
+		ready: make(chan bool, 1),
+		done:  make(chan bool),
+	}
+}
+
+func (o *Outbox) Push(p *Packet) bool {
+	o.Lock()
+	defer o.Unlock()
+
+	if o.closed() {
+		return false
+	}
+
+	if p.Key != "" {
+		if e, ok := o.keys[p.Key]; ok {
+			e.packet = p
+
+			return true
+		}
+	}
+
+	if o.size >= outboxSoftLimit {
+		if p.Priority == priorityCosmetic {
+			o.stats.Drop()
+
+			return true
+		}
+
+		o.shed()
+	}
+
+	if o.size >= outboxHardLimit {
+		o.stats.Drop()
+
+		return false
+	}
+
+	e := &entry{packet: p}
+	o.queues[p.Priority] = append(o.queues[p.Priority], e)
+	o.size++
+
+	if p.Key != "" {
+		o.keys[p.Key] = e
+	}
+
+	select {
+	case o.ready <- true:
+	default:
+	}
+
+	return true
+}
+
+func (o *Outbox) shed() {
+	q := o.queues[priorityCosmetic]
+
+	if len(q) == 0 {
+		return
+	}
+
+	o.forget(q[0])
+	o.queues[priorityCosmetic] = q[1:]
+	o.size--
+	o.stats.Drop()
+}
+
+func (o *Outbox) Pop() *Packet {
+	o.Lock()
+	defer o.Unlock()
+
+	for i := range o.queues {
+		q := o.queues[i]
+
+		if len(q) == 0 {
+			continue
+		}
+
+		o.forget(q[0])
+		o.queues[i] = q[1:]
+		o.size--
+
+		return q[0].packet
+	}
+
+	return nil
+}
+
+func (o *Outbox) forget(e *entry) {
+	if e.packet.Key != "" && o.keys[e.packet.Key] == e {
+		delete(o.keys, e.packet.Key)
+	}
+}
+
+func (o *Outbox) Clear() {
+	o.Lock()
+	defer o.Unlock()
+
+	for i := range o.queues {
+		for range o.queues[i] {
+			o.stats.Drop()
+		}
+
+		o.queues[i] = nil
+	}
+
+	o.keys = map[string]*entry{}
+	o.size = 0
+}
+
+func (o *Outbox) Len() int {
+	o.Lock()
+	defer o.Unlock()
+
+	return o.size
+}
+
+func (o *Outbox) Ready() <-chan bool {
+	return o.ready
+}
+
+func (o *Outbox) Done() <-chan bool {
+	return o.done
+}
+
+func (o *Outbox) Close() {
+	o.Lock()
+	defer o.Unlock()
+
+	if !o.closed() {
+		close(o.done)
+	}
+}
+
+func (o *Outbox) closed() bool {
+	select {
+	case <-o.done:
+		return true
+
+	default:
+		return false
+	}
+}
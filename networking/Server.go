@@ -1,150 +1,444 @@
-/*
-Zahhak2, a Golang console game.
-Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
-This program is free software: you can redistribute it and/or modify
-it under the terms of the GNU General Public License as published by
-the Free Software Foundation, either version 3 of the License, or
-(at your option) any later version.
-This program is distributed in the hope that it will be useful,
-but WITHOUT ANY WARRANTY; without even the implied warranty of
-MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
-GNU General Public License for more details.
-You should have received a copy of the GNU General Public License
-along with this program.  If not, see <http://www.gnu.org/licenses/>.
-*/
-
-package networking
-
-import (
-	"errors"
-	"fmt"
-	"log"
-	"net/http"
-	"time"
-
-	"github.com/gorilla/websocket"
-
-	z "../common"
-)
-
-type Server struct {
-	address      string
-	hub          *BroadcastHub
-	eventManager *z.EventManager
-}
-
-func NewServer(eventManager *z.EventManager, port string, incoming, outgoing chan []byte) *Server {
-	address := "127.0.0.1:" + port
-
-	hub := NewBroadcastHub(incoming, outgoing)
-
-	return &Server{
-		address:      address,
-		hub:          hub,
-		eventManager: eventManager,
-	}
-}
-
-func (s *Server) Run() {
-	s.hub.Run()
-
-	go func() {
-		http.HandleFunc("/", s.handler)
-		e := http.ListenAndServe(s.address, nil)
-
-		if e != nil {
-			panic(e)
-		}
-	}()
-}
-
-func (s *Server) Count() int {
-	return s.hub.Count()
-}
-
-func (s *Server) handler(w http.ResponseWriter, r *http.Request) {
-	address := r.RemoteAddr
-
-	defer s.recover(address)
-
-	defer log.Println("Server: " + address + " Closed websocket")
-
-	log.Println("Server: " + address + " New HTTP connection")
-
-	upgrader := websocket.Upgrader{
-		ReadBufferSize:  65335,
-		WriteBufferSize: 65335,
-		CheckOrigin: func(r *http.Request) bool {
-			return true
-		},
-	}
-
-	log.Println("Server: " + address + " Attempting to upgrade to websocket")
-
-	ws, e := upgrader.Upgrade(w, r, nil)
-
-	if e != nil {
-		z.LogError(errors.New("Server: " + address + " " + e.Error()))
-
-		return
-	}
-
-	log.Println("Server: " + address + " Calling to NewClient")
-
-	p, er := s.eventManager.Fire("NewClient")
-
-	if er != nil {
-		z.LogError(errors.New("Server: " + address + " " + er.Error()))
-
-		return
-	}
-
-	log.Println("Server: " + address + " Returning from NewClient")
-
-	v := p[0]
-	json := v.String()
-
-	bs := []byte(json)
-
-	log.Println("Server: " + address + " Writing to websocket")
-
-	if err := s.write(ws, websocket.TextMessage, bs); err != nil {
-		z.LogError(errors.New("Server: " + address + " " + err.Error()))
-
-		return
-	}
-
-	log.Println("Server: " + address + " Creating Connection")
-
-	c := &Connection{address: address, hub: s.hub, send: make(chan []byte, 1024), ws: ws}
-
-	log.Println("Server: " + address + " Registering Connection")
-
-	s.hub.register <- c
-
-	log.Println("Server: " + address + " Starting pumps")
-
-	go c.writePump()
-	c.readPump()
-}
-
-func (s *Server) write(ws *websocket.Conn, mt int, payload []byte) error {
-	ws.SetWriteDeadline(time.Now().Add(writeWait))
-
-	return ws.WriteMessage(mt, payload)
-}
-
-func (s *Server) recover(address string) {
-	if r := recover(); r != nil {
-		e, ok := r.(error)
-
-		if !ok {
-			e = fmt.Errorf("%v", r)
-			e = errors.New("Server: " + address + " " + e.Error())
-			z.LogPanic(e)
-		} else {
-			e = errors.New("Server: " + address + " " + e.Error())
-			z.LogPanic(e)
-		}
-	}
-}
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package networking
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	z "../common"
+)
+
+var (
+	servers     = map[string]*Server{}
+	serversLock sync.Mutex
+)
+
+type Server struct {
+	bind    string
+	port    string
+	address string
+	mux     *http.ServeMux
+	matches map[string]*Match
+	bans    map[string]bool
+	create  func(name string) error
+	started bool
+	admit   sync.Mutex
+
+	tls         *tls.Config
+	fingerprint string
+	impairment  *Impairment
+
+	sync.RWMutex
+}
+
+func NewServer(bind, port string) *Server {
+	address := net.JoinHostPort(bind, port)
+
+	return &Server{
+		bind:    bind,
+		port:    port,
+		address: address,
+		mux:     http.NewServeMux(),
+		matches: map[string]*Match{},
+		bans:    map[string]bool{},
+	}
+}
+
+func SharedServer(bind, port string) *Server {
+	serversLock.Lock()
+	defer serversLock.Unlock()
+
+	key := net.JoinHostPort(bind, port)
+	s, ok := servers[key]
+
+	if !ok {
+		s = NewServer(bind, port)
+		servers[key] = s
+	}
+
+	return s
+}
+
+func (s *Server) Run() error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.started {
+		return nil
+	}
+
+	host, e := BindAddress(s.bind)
+
+	if e != nil {
+		return e
+	}
+
+	s.address = net.JoinHostPort(host, s.port)
+
+	listener, e := net.Listen("tcp", s.address)
+
+	if e != nil {
+		return errors.New("could not listen on " + s.address + ": " + e.Error())
+	}
+
+	log.Println("Server: listening on " + listener.Addr().String())
+
+	if s.tls != nil {
+		listener = tls.NewListener(listener, s.tls)
+
+		log.Println("Server: TLS certificate fingerprint " + s.fingerprint)
+	}
+
+	s.mux.HandleFunc("/", s.handler)
+	s.mux.HandleFunc("/matches", s.list)
+	s.started = true
+
+	go func() {
+		e := http.Serve(listener, s.mux)
+
+		if e != nil {
+			z.LogError(errors.New("Server: " + s.address + " " + e.Error()))
+		}
+	}()
+
+	return nil
+}
+
+func (s *Server) Secure(certFile, keyFile string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.tls != nil {
+		return nil
+	}
+
+	if s.started {
+		return errors.New("server is already listening without TLS")
+	}
+
+	cert, e := LoadCertificate(certFile, keyFile)
+
+	if e != nil {
+		return e
+	}
+
+	s.tls = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	s.fingerprint = Fingerprint(cert.Certificate[0])
+
+	return nil
+}
+
+func (s *Server) Fingerprint() string {
+	s.RLock()
+	defer s.RUnlock()
+
+	return s.fingerprint
+}
+
+func (s *Server) Impair(i *Impairment) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.impairment = i
+}
+
+func (s *Server) impaired() *Impairment {
+	s.RLock()
+	defer s.RUnlock()
+
+	return s.impairment
+}
+
+func (s *Server) Open(eventManager *z.EventManager, session, name string, incoming chan []byte, outgoing *Outbox) *Match {
+	return NewMatch(s, eventManager, session, name, incoming, outgoing)
+}
+
+func (s *Server) OnCreate(create func(name string) error) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.create = create
+}
+
+func (s *Server) Matches() []*MatchInfo {
+	s.RLock()
+	defer s.RUnlock()
+
+	matches := make([]*Match, 0, len(s.matches))
+
+	for _, m := range s.matches {
+		matches = append(matches, m)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].created.Before(matches[j].created)
+	})
+
+	infos := make([]*MatchInfo, 0, len(matches))
+
+	for _, m := range matches {
+		infos = append(infos, m.Info())
+	}
+
+	return infos
+}
+
+func (s *Server) add(m *Match) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.matches[m.session] = m
+
+	log.Println("Server: opened match " + m.name + " " + m.session)
+}
+
+func (s *Server) remove(m *Match) {
+	s.Lock()
+	defer s.Unlock()
+
+	if _, ok := s.matches[m.session]; !ok {
+		return
+	}
+
+	delete(s.matches, m.session)
+
+	log.Println("Server: closed match " + m.name + " " + m.session)
+}
+
+func (s *Server) find(name string) *Match {
+	s.RLock()
+	defer s.RUnlock()
+
+	var found *Match
+
+	for _, m := range s.matches {
+		if name == "" {
+			if found == nil || m.created.Before(found.created) {
+				found = m
+			}
+		} else if m.session == name || strings.EqualFold(m.name, name) {
+			return m
+		}
+	}
+
+	return found
+}
+
+func (s *Server) match(name string) (*Match, error) {
+	if m := s.find(name); m != nil {
+		return m, nil
+	}
+
+	if name == "" {
+		return nil, NewRejection(z.REJECT_MATCH, "no match is running")
+	}
+
+	s.RLock()
+	create := s.create
+	s.RUnlock()
+
+	if create == nil {
+		return nil, NewRejection(z.REJECT_MATCH, "no match named "+name)
+	}
+
+	if e := create(name); e != nil {
+		return nil, e
+	}
+
+	if m := s.find(name); m != nil {
+		return m, nil
+	}
+
+	return nil, NewRejection(z.REJECT_MATCH, "could not create match "+name)
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	defer s.recover(r.RemoteAddr)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	json.NewEncoder(w).Encode(s.Matches())
+}
+
+func (s *Server) handler(w http.ResponseWriter, r *http.Request) {
+	address := r.RemoteAddr
+
+	defer s.recover(address)
+
+	defer log.Println("Server: " + address + " Closed websocket")
+
+	log.Println("Server: " + address + " New HTTP connection")
+
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  65335,
+		WriteBufferSize: 65335,
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+	}
+
+	log.Println("Server: " + address + " Attempting to upgrade to websocket")
+
+	ws, e := upgrader.Upgrade(w, r, nil)
+
+	if e != nil {
+		z.LogError(errors.New("Server: " + address + " " + e.Error()))
+
+		return
+	}
+
+	q := r.URL.Query()
+	spectator := q.Get("spectator") == "true"
+	name := q.Get("name")
+	host := remoteHost(address)
+	password := r.Header.Get(HEADER_PASSWORD)
+	token := r.Header.Get(HEADER_TOKEN)
+
+	if s.Banned(name, host) {
+		s.reject(ws, NewRejection(z.REJECT_BANNED, "you are banned from this server"))
+
+		return
+	}
+
+	match, e := s.match(q.Get("match"))
+
+	if e != nil {
+		rejection, ok := e.(*Rejection)
+
+		if !ok {
+			rejection = NewRejection(z.REJECT_MATCH, e.Error())
+		}
+
+		s.reject(ws, rejection)
+
+		return
+	}
+
+	log.Println("Server: " + address + " Joining match " + match.name)
+
+	c := s.join(ws, match, address, name, host, spectator, password, token)
+
+	if c == nil {
+		return
+	}
+
+	log.Println("Server: " + address + " Starting pumps")
+
+	go c.writePump()
+	c.readPump()
+
+	log.Println("Server: " + address + " Disconnected")
+
+	if name != "" {
+		match.eventManager.Fire("Disconnect", true, name)
+	}
+}
+
+func (s *Server) join(ws *websocket.Conn, match *Match, address, name, host string, spectator bool, password, token string) *Connection {
+	s.admit.Lock()
+	defer s.admit.Unlock()
+
+	if name != "" && len(match.hub.Find(name, "")) > 0 {
+		s.reject(ws, NewRejection(z.REJECT_TAKEN, "name "+name+" is already taken"))
+
+		return nil
+	}
+
+	log.Println("Server: " + address + " Calling to NewClient")
+
+	p, er := match.eventManager.Fire("NewClient", spectator, name, password, token)
+
+	if er != nil {
+		z.LogError(errors.New("Server: " + address + " " + er.Error()))
+
+		return nil
+	}
+
+	log.Println("Server: " + address + " Returning from NewClient")
+
+	v := p[0]
+	json := v.String()
+	accepted := p[1].Bool()
+
+	bs := []byte(json)
+
+	if !accepted {
+		log.Println("Server: " + address + " Rejecting connection")
+
+		s.write(ws, websocket.TextMessage, bs)
+		s.write(ws, websocket.CloseMessage, []byte{})
+		ws.Close()
+
+		return nil
+	}
+
+	log.Println("Server: " + address + " Creating Connection")
+
+	c := NewConnection(match.session, address, name, host, spectator, match.hub, transport(ws, s.impaired()))
+
+	log.Println("Server: " + address + " Queueing current state")
+
+	c.send.Push(&Packet{Priority: priorityCritical, Data: bs})
+
+	log.Println("Server: " + address + " Registering Connection")
+
+	match.hub.Add(c)
+
+	return c
+}
+
+func (s *Server) reject(ws *websocket.Conn, rejection *Rejection) {
+	log.Println("Server: " + ws.RemoteAddr().String() + " Rejecting connection: " + rejection.Reason)
+
+	bs, _ := rejection.Message().JSON()
+
+	s.write(ws, websocket.TextMessage, bs)
+	s.write(ws, websocket.CloseMessage, []byte{})
+	ws.Close()
+}
+
+func (s *Server) write(ws *websocket.Conn, mt int, payload []byte) error {
+	ws.SetWriteDeadline(time.Now().Add(writeWait))
+
+	return ws.WriteMessage(mt, payload)
+}
+
+func (s *Server) recover(address string) {
+	if r := recover(); r != nil {
+		e, ok := r.(error)
+
+		if !ok {
+			e = fmt.Errorf("%v", r)
+			e = errors.New("Server: " + address + " " + e.Error())
+			z.LogPanic(e)
+		} else {
+			e = errors.New("Server: " + address + " " + e.Error())
+			z.LogPanic(e)
+		}
+	}
+}
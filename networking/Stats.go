@@ -0,0 +1,145 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package networking
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+type Traffic struct {
+	MessagesIn  int64
+	MessagesOut int64
+	BytesIn     int64
+	BytesOut    int64
+	Dropped     int64
+}
+
+type Stats struct {
+	sync.Mutex
+
+	total   Traffic
+	last    Traffic
+	rate    Traffic
+	sampled time.Time
+	latency time.Duration
+}
+
+type Diagnostics struct {
+	Name    string
+	Address string
+	Latency time.Duration
+	Queue   int
+	Rate    Traffic
+	Total   Traffic
+}
+
+func NewStats() *Stats {
+	return &Stats{sampled: time.Now()}
+}
+
+func (s *Stats) In(bytes int) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.total.MessagesIn++
+	s.total.BytesIn += int64(bytes)
+}
+
+func (s *Stats) Out(bytes int) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.total.MessagesOut++
+	s.total.BytesOut += int64(bytes)
+}
+
+func (s *Stats) Drop() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.total.Dropped++
+}
+
+func (s *Stats) Ping() []byte {
+	return []byte(strconv.FormatInt(time.Now().UnixNano(), 10))
+}
+
+func (s *Stats) Pong(payload string) {
+	sent, e := strconv.ParseInt(payload, 10, 64)
+
+	if e != nil {
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.latency = time.Since(time.Unix(0, sent))
+}
+
+func (s *Stats) Latency() time.Duration {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.latency
+}
+
+func (s *Stats) Total() Traffic {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.total
+}
+
+func (s *Stats) Rate() Traffic {
+	s.Lock()
+	defer s.Unlock()
+
+	elapsed := time.Since(s.sampled)
+
+	if elapsed < time.Second {
+		return s.rate
+	}
+
+	millis := int64(elapsed / time.Millisecond)
+	per := func(now, then int64) int64 { return (now - then) * 1000 / millis }
+
+	s.rate = Traffic{
+		MessagesIn:  per(s.total.MessagesIn, s.last.MessagesIn),
+		MessagesOut: per(s.total.MessagesOut, s.last.MessagesOut),
+		BytesIn:     per(s.total.BytesIn, s.last.BytesIn),
+		BytesOut:    per(s.total.BytesOut, s.last.BytesOut),
+		Dropped:     per(s.total.Dropped, s.last.Dropped),
+	}
+
+	s.last = s.total
+	s.sampled = time.Now()
+
+	return s.rate
+}
+
+func (s *Stats) Diagnostics(name, address string, queue int) *Diagnostics {
+	return &Diagnostics{
+		Name:    name,
+		Address: address,
+		Latency: s.Latency(),
+		Queue:   queue,
+		Rate:    s.Rate(),
+		Total:   s.Total(),
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Zahhak2, a Golang console game.
+Copyright (C) 2021 Aryo Pehlewan aryopehlewan@hotmail.com
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package networking
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+type Security struct {
+	TLS         bool
+	CertFile    string
+	KeyFile     string
+	Fingerprint string
+}
+
+func Fingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	parts := make([]string, len(sum))
+
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+
+	return strings.Join(parts, ":")
+}
+
+func SameFingerprint(a, b string) bool {
+	clean := func(s string) string {
+		return strings.ToUpper(strings.NewReplacer(":", "", " ", "").Replace(s))
+	}
+
+	return clean(a) == clean(b)
+}
+
+func LoadCertificate(certFile, keyFile string) (tls.Certificate, error) {
+	_, e := os.Stat(certFile)
+
+	if os.IsNotExist(e) {
+		if e := GenerateCertificate(certFile, keyFile); e != nil {
+			return tls.Certificate{}, e
+		}
+	}
+
+	cert, e := tls.LoadX509KeyPair(certFile, keyFile)
+
+	if e != nil {
+		return cert, errors.New("could not load certificate " + certFile + ": " + e.Error())
+	}
+
+	return cert, nil
+}
+
+func GenerateCertificate(certFile, keyFile string) error {
+	key, e := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if e != nil {
+		return e
+	}
+
+	serial, e := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+
+	if e != nil {
+		return e
+	}
+
+	hostname, _ := os.Hostname()
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "Zahhak2 " + hostname, Organization: []string{"Zahhak2"}},
+		NotBefore:             time.Now().Add(-1 * time.Hour),
+		NotAfter:              time.Now().Add(certLifetime),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+
+	if hostname != "" {
+		template.DNSNames = append(template.DNSNames, hostname)
+	}
+
+	if ip, e := LocalIP(); e == nil {
+		template.IPAddresses = append(template.IPAddresses, net.ParseIP(ip))
+	}
+
+	der, e := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+
+	if e != nil {
+		return e
+	}
+
+	keyDer, e := x509.MarshalECPrivateKey(key)
+
+	if e != nil {
+		return e
+	}
+
+	if e := writePEM(certFile, "CERTIFICATE", der, 0644); e != nil {
+		return e
+	}
+
+	return writePEM(keyFile, "EC PRIVATE KEY", keyDer, 0600)
+}
+
+func writePEM(file, kind string, der []byte, mode os.FileMode) error {
+	f, e := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+
+	if e != nil {
+		return errors.New("could not write " + file + ": " + e.Error())
+	}
+
+	defer f.Close()
+
+	return pem.Encode(f, &pem.Block{Type: kind, Bytes: der})
+}
+
+func clientTLS(pin string) *tls.Config {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if pin == "" {
+		return config
+	}
+
+	config.InsecureSkipVerify = true
+	config.VerifyPeerCertificate = func(raw [][]byte, _ [][]*x509.Certificate) error {
+		if len(raw) == 0 {
+			return errors.New("server sent no certificate")
+		}
+
+		if !SameFingerprint(Fingerprint(raw[0]), pin) {
+			return errors.New("server certificate fingerprint " + Fingerprint(raw[0]) + " does not match the pinned one")
+		}
+
+		return nil
+	}
+
+	return config
+}
@@ -1,13 +1,13 @@
-package main
-
-func main() {
-	m := NewMusic()
-
-	m.Run()
-
-	m.Background()
-
-	for {
-
-	}
-}
+package main
+
+func main() {
+	m := NewMusic()
+
+	m.Run()
+
+	m.Background()
+
+	for {
+
+	}
+}